@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireDeadline(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Hour})
+	r, err := p.AcquireDeadline(time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := time.Now()
+	if _, err := p.AcquireDeadline(time.Now().Add(-time.Second)); err != ErrTimeout || time.Since(s) > 50*time.Millisecond {
+		t.Fatal(err)
+	}
+	s = time.Now()
+	if _, err := p.AcquireDeadline(time.Now().Add(50 * time.Millisecond)); err != ErrTimeout || time.Since(s) < 40*time.Millisecond {
+		t.Fatal(err)
+	}
+	go func() { time.Sleep(20 * time.Millisecond); p.Release(r) }()
+	if _, err := p.AcquireDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeout(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, AdaptiveTimeout: true, MinTimeout: 5 * time.Millisecond})
+	if p.EffectiveTimeout() != time.Second {
+		t.Fatal()
+	}
+	r, _ := p.Acquire()
+	p.Release(r)
+	if d := p.EffectiveTimeout(); d != time.Second {
+		t.Fatal("immediate acquire counted", d)
+	}
+	p.l.Lock()
+	p.ws.record(time.Millisecond)
+	p.l.Unlock()
+	if d := p.EffectiveTimeout(); d != 5*time.Millisecond {
+		t.Fatal(d)
+	}
+	p.l.Lock()
+	for i := 0; i < 200; i++ {
+		p.ws.record(20 * time.Millisecond)
+	}
+	p.l.Unlock()
+	if d := p.EffectiveTimeout(); d != 60*time.Millisecond {
+		t.Fatal(d)
+	}
+	p.l.Lock()
+	for i := 0; i < 200; i++ {
+		p.ws.record(time.Second)
+	}
+	p.l.Unlock()
+	if d := p.EffectiveTimeout(); d != time.Second {
+		t.Fatal(d)
+	}
+}
+
+func TestAdaptiveTimeoutFastAcquires(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, AdaptiveTimeout: true})
+	for i := 0; i < 200; i++ {
+		r, _ := p.Acquire()
+		p.Release(r)
+	}
+	if d := p.EffectiveTimeout(); d != time.Second {
+		t.Fatal(d)
+	}
+	r, _ := p.Acquire()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.Release(r)
+	}()
+	if _, err := p.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	// A single short wait does not go below the default floor
+	if d := p.EffectiveTimeout(); d < 100*time.Millisecond {
+		t.Fatal(d)
+	}
+}
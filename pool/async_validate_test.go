@@ -0,0 +1,28 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsyncValidate(t *testing.T) {
+	bad := make(chan Resource, 1)
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, AsyncValidate: true, OnInvalid: func(r Resource) { bad <- r }})
+	p.l.Lock()
+	r0 := p.idle[0].(*tres)
+	p.l.Unlock()
+	r0.ping.Store(false)
+	s := time.Now()
+	r, err := p.Acquire()
+	if err != nil || time.Since(s) > 10*time.Millisecond {
+		t.Fatal(err)
+	}
+	if <-bad != r {
+		t.Fatal()
+	}
+	p.Release(r)
+	r2, _ := p.Acquire()
+	if r2 == r || !r0.evicted.Load() {
+		t.Fatal("not replaced")
+	}
+}
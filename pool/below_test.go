@@ -0,0 +1,30 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireIfBelow(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 4, Timeout: time.Second})
+	a, err := p.AcquireIfBelow(0.5)
+	b, err2 := p.AcquireIfBelow(0.5)
+	if err != nil || err2 != nil {
+		t.Fatal(err, err2)
+	}
+	if _, err := p.AcquireIfBelow(0.5); err != ErrThresholdExceeded {
+		t.Fatal(err)
+	}
+	if r, err := p.AcquireIfBelow(0.75); err != nil {
+		t.Fatal(err)
+	} else {
+		p.Release(r)
+	}
+	p.Release(a)
+	if r, err := p.AcquireIfBelow(0.5); err != nil {
+		t.Fatal(err)
+	} else {
+		p.Release(r)
+	}
+	p.Release(b)
+}
@@ -0,0 +1,31 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type slowRes struct{ *tres }
+
+func (s slowRes) Add() (Resource, error) {
+	time.Sleep(50 * time.Millisecond)
+	r, _ := s.tres.Add()
+	return slowRes{r.(*tres)}, nil
+}
+
+func TestAcquireBestEffort(t *testing.T) {
+	p, _ := Initialize(slowRes{newT()}, Options{PoolSize: 1, Timeout: time.Second, TestOnReturn: true})
+	r, _ := p.Acquire()
+	r.(slowRes).ping.Store(false)
+	go func() { time.Sleep(10 * time.Millisecond); p.Release(r) }()
+	n, graced, err := p.AcquireBestEffort(20 * time.Millisecond)
+	if err != nil || !graced || n == r {
+		t.Fatal(err, graced)
+	}
+	p.Release(n)
+	p.Acquire()
+	_, graced, err = p.AcquireBestEffort(20 * time.Millisecond)
+	if err != ErrTimeout || graced {
+		t.Fatal(err, graced)
+	}
+}
@@ -0,0 +1,59 @@
+package pool
+
+import "time"
+
+// Internal function periodically reclaiming resources held for
+// longer than Options.MaxBorrowDuration.
+func (p *Pool) sweep() {
+	tick := time.NewTicker(p.o.MaxBorrowDuration / 2)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			p.reclaim()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Internal function reclaiming resources that have not been
+// released within Options.MaxBorrowDuration.
+// The resources are evicted and replaced so the pool does not
+// drain. Their holders get ErrReclaimed on Release, but may
+// still be using them, which is why reclaiming is opt-in.
+func (p *Pool) reclaim() {
+	now := time.Now()
+	var stale []Resource
+	p.l.Lock()
+	for _, m := range p.meta {
+		if m.reclaimed || m.reserved || m.borrowed.IsZero() || now.Sub(m.borrowed) < p.o.MaxBorrowDuration {
+			continue
+		}
+		p.emit(EventEvict, m.r, now.Sub(m.borrowed))
+		m.reclaimed = true
+		stale = append(stale, m.r)
+	}
+	peer := p.peer()
+	p.l.Unlock()
+	for _, r := range stale {
+		p.logf(LogWarn, "reclaiming resource %v held longer than %v", r, p.o.MaxBorrowDuration)
+		p.evict(r)
+		if n, err := p.create(p.ctx, peer); err == nil {
+			p.fill(n)
+		}
+	}
+}
+
+// Internal function checking if a resource was reclaimed, in
+// which case the pool forgets about it.
+func (p *Pool) reclaimed(r Resource) bool {
+	p.l.Lock()
+	defer p.l.Unlock()
+	m, ok := p.meta[identity(r)]
+	if !ok || !m.reclaimed {
+		return false
+	}
+	p.forget(r)
+	return true
+}
@@ -0,0 +1,18 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireBudget(t *testing.T) {
+	n := 0
+	f := func() (Resource, error) { n++; return nil, errors.New("x") }
+	p, _ := InitializeWith(f, WithPoolSize(1), WithLazy())
+	st := time.Now()
+	_, err := p.AcquireBudget(100*time.Millisecond, 4)
+	if err == nil || n != 4 || time.Since(st) > 120*time.Millisecond || time.Since(st) < 70*time.Millisecond {
+		t.Fatal(err, n, time.Since(st))
+	}
+}
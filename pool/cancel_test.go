@@ -0,0 +1,57 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCancelCreations(t *testing.T) {
+	slow := newT()
+	p, _ := InitializeWith(func() (Resource, error) { time.Sleep(200 * time.Millisecond); return slow, nil }, WithPoolSize(2), WithLazy())
+	go p.Warm(context.Background(), 2)
+	time.Sleep(20 * time.Millisecond)
+	st := time.Now()
+	p.Close()
+	if time.Since(st) > 50*time.Millisecond {
+		t.Fatal("slow close")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if !slow.evicted.Load() {
+		t.Fatal("late not evicted")
+	}
+}
+
+// Resource whose Evict looks at the pool it is evicted from.
+type callbackRes struct {
+	*tres
+	p **Pool
+}
+
+func (c *callbackRes) Add() (Resource, error) {
+	r, _ := c.tres.Add()
+	return &callbackRes{r.(*tres), c.p}, nil
+}
+func (c *callbackRes) Evict() bool {
+	(*c.p).Stats()
+	return c.tres.Evict()
+}
+
+func TestCloseEvictsUnlocked(t *testing.T) {
+	c := &callbackRes{newT(), new(*Pool)}
+	p, _ := Initialize(c, Options{PoolSize: 2, Timeout: time.Second})
+	*c.p = p
+	done := make(chan struct{})
+	go func() {
+		p.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close deadlocked in Evict")
+	}
+	if n := *c.evicts; n != 2 {
+		t.Fatal(n)
+	}
+}
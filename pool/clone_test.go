@@ -0,0 +1,24 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type cres struct {
+	*tres
+	clones *int
+}
+
+func (c *cres) Clone() (Resource, error) { *c.clones++; return &cres{newT(), c.clones}, nil }
+
+func TestClone(t *testing.T) {
+	n := 0
+	p, _ := InitializeWith(func() (Resource, error) { return &cres{newT(), &n}, nil }, WithPoolSize(2), WithTimeout(time.Second), WithTestOnReturn())
+	a, _ := p.Acquire()
+	a.(*cres).ping.Store(false)
+	p.Release(a)
+	if n != 1 {
+		t.Fatal(n)
+	}
+}
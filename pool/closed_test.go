@@ -0,0 +1,27 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReleaseAfterClose(t *testing.T) {
+	for _, m := range []CloseMode{CloseEvict, CloseError} {
+		r := newT()
+		p, _ := Initialize(r, Options{PoolSize: 2, Timeout: 10 * time.Millisecond, ReleaseAfterClose: m})
+		a, _ := p.Acquire()
+		if err := p.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Acquire(); err != ErrPoolClosed {
+			t.Fatal(err)
+		}
+		err := p.Release(a)
+		if m == CloseError && err != ErrPoolClosed {
+			t.Fatal(err)
+		}
+		if m == CloseEvict && (err != nil || !a.(*tres).evicted.Load()) {
+			t.Fatal("not evicted")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package pool
+
+// Evict idle resources the pool does not need in steady state.
+// The pool shrinks towards the most resources in use at once
+// since the last Compact but keeps at least Options.MinIdle idle
+// ones and those dedicated to a key. Returns how many resources
+// were evicted. Call it in quiet periods to give back what an
+// overflow or a peak made the pool grow.
+func (p *Pool) Compact() int {
+	p.l.Lock()
+	if p.closed {
+		p.l.Unlock()
+		return 0
+	}
+	var v []Resource
+	kept := p.idle[:0]
+	for _, r := range p.idle {
+		if p.s > p.peak && int64(len(p.idle)-len(v)) > p.o.MinIdle && !p.dedicated(r) {
+			p.s--
+			p.forget(r)
+			v = append(v, r)
+			continue
+		}
+		kept = append(kept, r)
+	}
+	p.idle = kept
+	p.peak = p.s - int64(len(p.idle))
+	p.l.Unlock()
+	for _, r := range v {
+		p.evict(r)
+	}
+	p.logf(LogDebug, "compacted %d resources", len(v))
+	return len(v)
+}
@@ -0,0 +1,32 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, MaxOverflow: 4, MinIdle: 1})
+	var rs []Resource
+	for i := 0; i < 6; i++ {
+		r, err := p.Acquire()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, r)
+	}
+	for _, r := range rs {
+		p.Release(r)
+	}
+	if n := p.Compact(); n != 0 {
+		t.Fatal("spike evicted", n)
+	}
+	r, _ := p.Acquire()
+	p.Release(r)
+	if n := p.Compact(); n != 5 || p.Stats().Size != 1 || p.Stats().Idle != 1 {
+		t.Fatal(n, p.Stats())
+	}
+	if n := p.Compact(); n != 0 {
+		t.Fatal(n)
+	}
+}
@@ -0,0 +1,41 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAcquireContext(t *testing.T) {
+	cancelled := make(chan struct{})
+	f := func(ctx context.Context) (Resource, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+	p, err := Initialize(nil, Options{PoolSize: 1, Timeout: 5 * time.Second, Lazy: true, FactoryCtx: f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, c := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer c()
+	_, err = p.AcquireContext(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+	<-cancelled
+	if p.Stats().Size != 0 {
+		t.Fatal(p.Stats())
+	}
+	// waiting path
+	p2, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 5 * time.Second})
+	p2.Acquire()
+	ctx2, c2 := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer c2()
+	if _, err := p2.AcquireContext(ctx2); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+	if p2.Stats().Waiters != 0 {
+		t.Fatal("waiter left")
+	}
+}
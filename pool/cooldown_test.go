@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvictionCooldown(t *testing.T) {
+	b := newT()
+	b.evict = true
+	p, _ := Initialize(b, Options{PoolSize: 1, Timeout: time.Second, EvictionCooldown: 50 * time.Millisecond})
+	p.refreshPool(nil)
+	if atomic.LoadInt64(b.evicts) != 1 {
+		t.Fatal()
+	}
+	p.refreshPool(nil)
+	if atomic.LoadInt64(b.evicts) != 1 {
+		t.Fatal("evicted within cooldown")
+	}
+	time.Sleep(60 * time.Millisecond)
+	p.refreshPool(nil)
+	if atomic.LoadInt64(b.evicts) != 2 {
+		t.Fatal("not evicted after cooldown")
+	}
+}
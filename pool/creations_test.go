@@ -0,0 +1,46 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countRes struct {
+	*tres
+	in, max *int32
+}
+
+func (s countRes) Add() (Resource, error) {
+	n := atomic.AddInt32(s.in, 1)
+	for {
+		m := atomic.LoadInt32(s.max)
+		if n <= m || atomic.CompareAndSwapInt32(s.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	atomic.AddInt32(s.in, -1)
+	r, _ := s.tres.Add()
+	return countRes{r.(*tres), s.in, s.max}, nil
+}
+
+func TestMaxConcurrentCreations(t *testing.T) {
+	s := countRes{newT(), new(int32), new(int32)}
+	p, _ := Initialize(s, Options{PoolSize: 20, Lazy: true, MaxOverflow: 10, Timeout: 5 * time.Second, MaxConcurrentCreations: 3})
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Acquire(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if m := atomic.LoadInt32(s.max); m != 3 {
+		t.Fatal(m)
+	}
+}
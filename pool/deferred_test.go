@@ -0,0 +1,53 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type deferRes struct {
+	*tres
+	tries *int64
+	done  *atomic.Bool
+}
+
+func (s *deferRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &deferRes{r.(*tres), s.tries, s.done}, nil
+}
+
+func (s *deferRes) TryEvict() error {
+	if atomic.AddInt64(s.tries, 1) == 1 {
+		return ErrEvictDeferred
+	}
+	s.done.Store(true)
+	return nil
+}
+
+func TestDeferredEvictor(t *testing.T) {
+	b := &deferRes{newT(), new(int64), new(atomic.Bool)}
+	p, _ := Initialize(b, Options{PoolSize: 1, Timeout: time.Second})
+	p.Close()
+	if b.done.Load() {
+		t.Fatal("early")
+	}
+	time.Sleep(3 * evictRetry)
+	if !b.done.Load() || *b.tries != 2 {
+		t.Fatal(*b.tries)
+	}
+}
+
+func TestDeferredEvictorRefresh(t *testing.T) {
+	b := &deferRes{newT(), new(int64), new(atomic.Bool)}
+	p, _ := Initialize(b, Options{PoolSize: 1, Timeout: time.Second})
+	p.idle[0].(*deferRes).ping.Store(false)
+	p.refreshPool(nil)
+	if atomic.LoadInt64(b.tries) != 1 || atomic.LoadInt64(b.evicts) != 0 {
+		t.Fatal(atomic.LoadInt64(b.tries), atomic.LoadInt64(b.evicts))
+	}
+	time.Sleep(3 * evictRetry)
+	if !b.done.Load() || p.Stats().Idle != 1 {
+		t.Fatal(p.Stats())
+	}
+}
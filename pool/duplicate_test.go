@@ -0,0 +1,27 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuplicateResource(t *testing.T) {
+	same := newT()
+	f := func() (Resource, error) { return same, nil }
+	if _, err := InitializeWith(f, WithPoolSize(2)); err != ErrDuplicateResource {
+		t.Fatal(err)
+	}
+	// The failed Initialize evicted it
+	same = newT()
+	p, _ := InitializeWith(f, WithPoolSize(2), WithLazy(), WithTimeout(10*time.Millisecond))
+	r, err := p.Acquire()
+	if err != nil || r != same {
+		t.Fatal(err)
+	}
+	if _, err := p.Acquire(); err != ErrDuplicateResource {
+		t.Fatal(err)
+	}
+	if p.Stats().Size != 1 || same.evicted.Load() {
+		t.Fatal(p.Stats())
+	}
+}
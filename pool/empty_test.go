@@ -0,0 +1,19 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailIfEmpty(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Hour, FailIfEmpty: true})
+	r, _ := p.Acquire()
+	s := time.Now()
+	if _, err := p.Acquire(); err != ErrPoolEmpty || time.Since(s) > 10*time.Millisecond {
+		t.Fatal(err)
+	}
+	p.Release(r)
+	if _, err := p.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+package pool
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Kind of pool event.
+	EventType int
+	// Something that happened in the pool.
+	// Events are sent to Options.Events without blocking, they
+	// are dropped if the channel is full.
+	Event struct {
+		Type     EventType
+		ID       uint64        // Resource involved, 0 if none
+		Time     time.Time     // When it happened
+		Duration time.Duration // Wait for acquire, hold for release, timeout for timeout
+	}
+)
+
+const (
+	EventCreate  EventType = iota + 1 // A resource was created
+	EventAcquire                      // A resource was acquired
+	EventRelease                      // A resource was released
+	EventEvict                        // A resource was evicted
+	EventTimeout                      // An acquire timed out
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventCreate:
+		return "create"
+	case EventAcquire:
+		return "acquire"
+	case EventRelease:
+		return "release"
+	case EventEvict:
+		return "evict"
+	case EventTimeout:
+		return "timeout"
+	}
+	return fmt.Sprintf("event(%d)", int(t))
+}
+
+// Internal function publishing an event about r, which may be nil.
+// Must be called with the lock held.
+func (p *Pool) emit(t EventType, r Resource, d time.Duration) {
+	if p.o.Events == nil && p.o.HistorySize <= 0 {
+		return
+	}
+	e := Event{Type: t, Time: time.Now(), Duration: d}
+	if m, ok := p.meta[identity(r)]; ok {
+		e.ID = m.id
+	}
+	if p.o.HistorySize > 0 {
+		p.remember(e)
+	}
+	if p.o.Events != nil {
+		select {
+		case p.o.Events <- e:
+		default:
+		}
+	}
+}
+
+// Internal function keeping an event in the history ring buffer,
+// overwriting the oldest once Options.HistorySize is reached.
+// Must be called with the lock held.
+func (p *Pool) remember(e Event) {
+	if len(p.hist) < p.o.HistorySize {
+		p.hist = append(p.hist, e)
+		return
+	}
+	p.hist[p.hn] = e
+	p.hn = (p.hn + 1) % len(p.hist)
+}
+
+// Recent events, oldest first.
+// Holds at most Options.HistorySize events and none unless it is
+// set. Useful to see what the pool was doing right before a crash
+// without a live subscriber.
+func (p *Pool) History() []Event {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	h := make([]Event, 0, len(p.hist))
+	h = append(h, p.hist[p.hn:]...)
+	return append(h, p.hist[:p.hn]...)
+}
@@ -0,0 +1,29 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	ev := make(chan Event, 100)
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond, Events: ev})
+	a, _ := p.Acquire()
+	p.Acquire()
+	p.Release(a)
+	p.Close()
+	close(ev)
+	var got []EventType
+	for e := range ev {
+		got = append(got, e.Type)
+	}
+	want := []EventType{EventCreate, EventAcquire, EventTimeout, EventRelease, EventEvict}
+	if len(got) != len(want) {
+		t.Fatal(got)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatal(got)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Resource that can not always be evicted straight away, e.g.
+	// a connection with a response in flight. The pool calls
+	// TryEvict instead of Evict when it gets rid of the resource
+	// and keeps retrying until it stops returning ErrEvictDeferred.
+	// The eviction test checks it with Ping instead of Evict.
+	DeferredEvictor interface {
+		Resource
+		TryEvict() error // Evict, ErrEvictDeferred if it has to wait
+	}
+	// Resource whose Evict only starts its shutdown, e.g. a
+	// connection flushing buffers. The pool calls EvictAsync instead
+	// and waits for the channel to deliver, up to
+	// Options.EvictTimeout, before counting the resource as gone;
+	// Close returns once all of them are. The eviction test checks
+	// it with Ping instead of Evict.
+	AsyncEvictor interface {
+		Resource
+		EvictAsync() <-chan error // Start evicting, delivers once the resource is gone
+	}
+	// Asynchronous evictions in flight.
+	pending struct {
+		l    sync.Mutex    // guards n and idle
+		n    int           // evictions not confirmed yet
+		idle chan struct{} // closed once n drops to 0
+		late atomic.Int32  // evictions that timed out since the last Close
+	}
+	// Resources whose eviction was put off.
+	deferred struct {
+		l   sync.Mutex        // guards the fields below
+		q   []DeferredEvictor // resources to evict
+		run bool              // retry routine running
+	}
+)
+
+const evictRetry = 100 * time.Millisecond // wait between attempts to evict deferred resources
+
+// Internal function evicting a resource the pool got rid of.
+// A DeferredEvictor that can not be evicted yet is queued up and
+// retried in the background, even after Close.
+func (p *Pool) evict(r Resource) {
+	if a, ok := r.(AsyncEvictor); ok {
+		p.confirm(a)
+		return
+	}
+	d, ok := r.(DeferredEvictor)
+	if !ok {
+		r.Evict()
+		return
+	}
+	err := d.TryEvict()
+	if err == nil {
+		return
+	}
+	if err != ErrEvictDeferred {
+		p.logf(LogWarn, "evicting resource %v: %v", r, err)
+		return
+	}
+	p.logf(LogDebug, "eviction of resource %v deferred", r)
+	p.dq.l.Lock()
+	defer p.dq.l.Unlock()
+	p.dq.q = append(p.dq.q, d)
+	if !p.dq.run {
+		p.dq.run = true
+		go p.retryEvict()
+	}
+}
+
+// Internal function running the eviction test of a resource,
+// returning true if it is gone. Evict both tests and evicts, so
+// resources evicted in their own way are tested with Ping and
+// evicted through evict.
+func (p *Pool) test(r Resource) bool {
+	switch r.(type) {
+	case DeferredEvictor, AsyncEvictor:
+		if p.ping(r) {
+			return false
+		}
+		p.evict(r)
+		return true
+	}
+	return r.Evict()
+}
+
+// Internal function starting the eviction of an AsyncEvictor and
+// waiting for it in the background.
+func (p *Pool) confirm(a AsyncEvictor) {
+	c := a.EvictAsync()
+	p.ae.l.Lock()
+	if p.ae.n == 0 {
+		p.ae.idle = make(chan struct{})
+	}
+	p.ae.n++
+	p.ae.l.Unlock()
+	go func() {
+		defer func() {
+			p.ae.l.Lock()
+			if p.ae.n--; p.ae.n == 0 {
+				close(p.ae.idle)
+			}
+			p.ae.l.Unlock()
+		}()
+		var timeout <-chan time.Time
+		if p.o.EvictTimeout > 0 {
+			t := time.NewTimer(p.o.EvictTimeout)
+			defer t.Stop()
+			timeout = t.C
+		}
+		select {
+		case err := <-c:
+			if err != nil {
+				p.logf(LogWarn, "evicting resource %v: %v", a, err)
+			}
+		case <-timeout:
+			p.ae.late.Add(1)
+			p.logf(LogWarn, "eviction of resource %v not confirmed after %v", a, p.o.EvictTimeout)
+		}
+	}()
+}
+
+// Internal function waiting for the asynchronous evictions in
+// flight. Returns ErrEvictTimeout if one was not confirmed in time
+// since the last call.
+func (p *Pool) settle() error {
+	p.ae.l.Lock()
+	idle := p.ae.idle
+	n := p.ae.n
+	p.ae.l.Unlock()
+	if n > 0 {
+		<-idle
+	}
+	if p.ae.late.Swap(0) > 0 {
+		return ErrEvictTimeout
+	}
+	return nil
+}
+
+// Internal function retrying deferred evictions until none is
+// left.
+func (p *Pool) retryEvict() {
+	for {
+		time.Sleep(evictRetry)
+		p.dq.l.Lock()
+		q := p.dq.q
+		p.dq.q = nil
+		p.dq.l.Unlock()
+		var left []DeferredEvictor
+		for _, d := range q {
+			err := d.TryEvict()
+			if err == ErrEvictDeferred {
+				left = append(left, d)
+			} else if err != nil {
+				p.logf(LogWarn, "evicting resource %v: %v", d, err)
+			}
+		}
+		p.dq.l.Lock()
+		p.dq.q = append(left, p.dq.q...)
+		if len(p.dq.q) == 0 {
+			p.dq.run = false
+			p.dq.l.Unlock()
+			return
+		}
+		p.dq.l.Unlock()
+	}
+}
@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type asyncRes struct {
+	*tres
+	wait time.Duration
+	gone *int64
+}
+
+func (s *asyncRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &asyncRes{r.(*tres), s.wait, s.gone}, nil
+}
+func (s *asyncRes) EvictAsync() <-chan error {
+	c := make(chan error, 1)
+	go func() {
+		time.Sleep(s.wait)
+		atomic.AddInt64(s.gone, 1)
+		c <- errors.New("closed late")
+	}()
+	return c
+}
+
+func TestAsyncEvictor(t *testing.T) {
+	gone := new(int64)
+	p, _ := Initialize(&asyncRes{newT(), 50 * time.Millisecond, gone}, Options{PoolSize: 3})
+	s := time.Now()
+	if err := p.Close(); err != nil || atomic.LoadInt64(gone) != 3 || time.Since(s) < 50*time.Millisecond {
+		t.Fatal(err, *gone)
+	}
+	p, _ = Initialize(&asyncRes{newT(), time.Second, new(int64)}, Options{PoolSize: 2, EvictTimeout: 20 * time.Millisecond})
+	s = time.Now()
+	if err := p.Close(); err != ErrEvictTimeout || time.Since(s) > 500*time.Millisecond {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncEvictorRefresh(t *testing.T) {
+	gone := new(int64)
+	p, _ := Initialize(&asyncRes{newT(), 50 * time.Millisecond, gone}, Options{PoolSize: 1})
+	p.idle[0].(*asyncRes).ping.Store(false)
+	p.refreshPool(nil)
+	if atomic.LoadInt64(gone) != 0 || p.Stats().Idle != 1 {
+		t.Fatal(p.Stats())
+	}
+	// Close waits for the resource evicted by the refresh too
+	if err := p.Close(); err != nil || atomic.LoadInt64(gone) != 2 {
+		t.Fatal(err, atomic.LoadInt64(gone))
+	}
+}
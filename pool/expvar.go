@@ -0,0 +1,42 @@
+package pool
+
+import (
+	"expvar"
+	"sync"
+)
+
+var (
+	// Pools published by PublishExpvar, by name.
+	published   = make(map[string]*Pool)
+	publishedMu sync.Mutex
+)
+
+// Publish the stats of the pool with expvar under name, so they
+// show on /debug/vars. Publishing another pool under the same
+// name replaces the previous one; a name expvar already uses for
+// something else is left alone.
+func (p *Pool) PublishExpvar(name string) {
+	publishedMu.Lock()
+	defer publishedMu.Unlock()
+	if _, ok := published[name]; !ok {
+		if expvar.Get(name) != nil {
+			p.logf(LogWarn, "expvar %q already in use", name)
+			return
+		}
+		expvar.Publish(name, expvar.Func(func() interface{} {
+			publishedMu.Lock()
+			q := published[name]
+			publishedMu.Unlock()
+			s := q.Stats()
+			return map[string]int64{
+				"size":     s.Size,
+				"in_use":   s.InUse,
+				"idle":     s.Idle,
+				"waiters":  s.Waiters,
+				"acquires": s.Acquires,
+				"timeouts": s.Timeouts,
+			}
+		}))
+	}
+	published[name] = p
+}
@@ -0,0 +1,40 @@
+package pool
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Runs of the expvar tests, expvar names can not be published twice.
+var expvarRuns int64
+
+func TestPublish(t *testing.T) {
+	name := fmt.Sprintf("%s%d", t.Name(), atomic.AddInt64(&expvarRuns, 1))
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond})
+	p.PublishExpvar(name)
+	p.PublishExpvar(name)
+	r, _ := p.Acquire()
+	p.Acquire()
+	var m map[string]int64
+	json.Unmarshal([]byte(expvar.Get(name).String()), &m)
+	if m["size"] != 1 || m["in_use"] != 1 || m["acquires"] != 1 || m["timeouts"] != 1 {
+		t.Fatal(m)
+	}
+	p.Release(r)
+	q, _ := Initialize(newT(), Options{PoolSize: 3})
+	q.PublishExpvar(name)
+	json.Unmarshal([]byte(expvar.Get(name).String()), &m)
+	if m["size"] != 3 {
+		t.Fatal(m)
+	}
+	// A name used for something else is left alone
+	v := expvar.NewInt(name + "x")
+	p.PublishExpvar(name + "x")
+	if expvar.Get(name+"x") != v {
+		t.Fatal("replaced")
+	}
+}
@@ -0,0 +1,262 @@
+package pool
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type (
+	// Resource that can be derived from an existing one.
+	// When a resource is evicted the pool clones a healthy idle
+	// one instead of creating a replacement from scratch. Clone
+	// may be called while the resource is idle or acquired.
+	CloneableResource interface {
+		Resource
+		Clone() (Resource, error) // Create a resource from this one
+	}
+	// Factory creating a resource within ctx.
+	ContextFactory func(ctx context.Context) (Resource, error)
+	// State of the factories creating resources.
+	factory struct {
+		l     sync.Mutex    // guards the fields below, never held across a call to a factory
+		fails int           // consecutive failures of the primary factory
+		use2  bool          // fallback factory in use
+		since time.Time     // last failed attempt of the primary while on fallback
+		lat   time.Duration // moving average of creation latency
+		st    []FactoryStat // primary and fallback factory counters, Latency holds the total
+		sem   chan struct{} // a token per creation in flight, nil without Options.MaxConcurrentCreations
+	}
+	// Creations by one of the factories of a pool.
+	FactoryStat struct {
+		Attempts  int64         // Calls to the factory
+		Successes int64         // Calls that created a resource
+		Failures  int64         // Calls that returned an error
+		Latency   time.Duration // Average time a successful call took
+	}
+)
+
+// Internal function creating a resource with the active factory.
+// The fallback factory takes over once the primary factory failed
+// Options.FallbackAfter times in a row. While on fallback the primary
+// is tried again every Options.FallbackRetry and back in use as soon
+// as it succeeds.
+func (p *Pool) add(ctx context.Context) (Resource, error) {
+	p.fa.l.Lock()
+	f := p.f
+	primary := !p.fa.use2 || time.Since(p.fa.since) >= p.o.FallbackRetry
+	p.fa.l.Unlock()
+	if p.o.FallbackFactory == nil {
+		return p.call(0, func() (Resource, error) { return f(ctx) })
+	}
+	if primary {
+		r, err := p.call(0, func() (Resource, error) { return f(ctx) })
+		p.fa.l.Lock()
+		if err == nil {
+			if p.fa.use2 {
+				p.logf(LogInfo, "primary factory recovered")
+			}
+			p.fa.fails = 0
+			p.fa.use2 = false
+			p.fa.l.Unlock()
+			return r, nil
+		}
+		p.fa.fails++
+		if !p.fa.use2 && p.fa.fails >= p.o.FallbackAfter {
+			p.logf(LogWarn, "primary factory failed %d times, using fallback: %v", p.fa.fails, err)
+			p.fa.use2 = true
+		}
+		p.fa.since = time.Now()
+		use2 := p.fa.use2
+		p.fa.l.Unlock()
+		if !use2 {
+			return nil, err
+		}
+	}
+	return p.call(1, p.o.FallbackFactory)
+}
+
+// Internal function calling the factory with index i, 0 for the
+// primary and 1 for the fallback, counting the outcome.
+func (p *Pool) call(i int, f func() (Resource, error)) (Resource, error) {
+	start := time.Now()
+	r, err := f()
+	p.fa.l.Lock()
+	defer p.fa.l.Unlock()
+	if p.fa.st == nil {
+		p.fa.st = make([]FactoryStat, 2)
+	}
+	s := &p.fa.st[i]
+	s.Attempts++
+	if err != nil {
+		s.Failures++
+	} else {
+		s.Successes++
+		s.Latency += time.Since(start)
+	}
+	return r, err
+}
+
+// Creation counters of the factories, the primary first and
+// Options.FallbackFactory second if there is one. Tells which
+// backend degraded when the factories point to different ones.
+func (p *Pool) FactoryStats() []FactoryStat {
+	n := 1
+	if p.o.FallbackFactory != nil {
+		n = 2
+	}
+	v := make([]FactoryStat, n)
+	p.fa.l.Lock()
+	copy(v, p.fa.st)
+	p.fa.l.Unlock()
+	for i := range v {
+		if v[i].Successes > 0 {
+			v[i].Latency /= time.Duration(v[i].Successes)
+		}
+	}
+	return v
+}
+
+// Internal function creating a replacement resource.
+// Clones peer when it is a CloneableResource and falls back to
+// the factory otherwise or if cloning fails.
+// Returns ErrPoolClosed as soon as the pool is closed, or the
+// error of ctx once it is done, without waiting for a hung
+// factory; a resource it still creates is evicted straight away.
+func (p *Pool) renew(ctx context.Context, peer Resource) (Resource, error) {
+	type result struct {
+		r   Resource
+		err error
+	}
+	c := make(chan result, 1)
+	go func() {
+		r, err := p.derive(ctx, peer)
+		c <- result{r, err}
+	}()
+	select {
+	case v := <-c:
+		return v.r, v.err
+	case <-ctx.Done():
+		go func() {
+			if v := <-c; v.r != nil {
+				p.logf(LogDebug, "evicting resource %v created too late", v.r)
+				p.evict(v.r)
+			}
+		}()
+		if p.ctx.Err() != nil {
+			return nil, ErrPoolClosed
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Internal function cloning peer or calling the factory, timing
+// how long successful creations take. Waits for one of the
+// Options.MaxConcurrentCreations slots first.
+func (p *Pool) derive(ctx context.Context, peer Resource) (Resource, error) {
+	if p.fa.sem != nil {
+		select {
+		case p.fa.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() { <-p.fa.sem }()
+	}
+	start := time.Now()
+	r, err := p.clone(ctx, peer)
+	if err == nil && isNil(r) {
+		return nil, ErrNilResource
+	}
+	if err == nil {
+		d := time.Since(start)
+		p.fa.l.Lock()
+		if p.fa.lat == 0 {
+			p.fa.lat = d
+		} else {
+			// Exponential moving average, recent creations weigh 1/5
+			p.fa.lat += (d - p.fa.lat) / 5
+		}
+		p.fa.l.Unlock()
+	}
+	return r, err
+}
+
+// Internal function checking for a nil resource, including a nil
+// pointer in a non-nil interface.
+func isNil(r Resource) bool {
+	if r == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(r); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Moving average of the time it takes to create a resource.
+// Returns 0 until a resource has been created. Accounts for
+// creation cost in warm up and scaling decisions.
+func (p *Pool) CreationLatency() time.Duration {
+	p.fa.l.Lock()
+	defer p.fa.l.Unlock()
+	return p.fa.lat
+}
+
+// Internal function cloning peer if it is a CloneableResource.
+func (p *Pool) clone(ctx context.Context, peer Resource) (Resource, error) {
+	if c, ok := peer.(CloneableResource); ok {
+		r, err := c.Clone()
+		if err == nil {
+			return r, nil
+		}
+		p.logf(LogWarn, "cloning resource: %v", err)
+	}
+	return p.add(ctx)
+}
+
+// Internal function deriving a context from ctx that is also
+// cancelled on Close.
+func (p *Pool) join(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(p.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Internal function adapting a factory to ignore the context.
+func plain(f func() (Resource, error)) ContextFactory {
+	return func(context.Context) (Resource, error) {
+		return f()
+	}
+}
+
+// Internal function picking an idle resource to clone from.
+// Must be called with the lock held.
+func (p *Pool) peer() Resource {
+	if len(p.idle) == 0 {
+		return nil
+	}
+	return p.idle[0]
+}
+
+// Internal function reporting if the fallback factory is in use.
+func (p *Pool) fallback() bool {
+	p.fa.l.Lock()
+	defer p.fa.l.Unlock()
+	return p.fa.use2
+}
+
+// Replace the factory creating resources.
+// Every resource created from now on, including replacements of
+// evicted ones, comes from f. Existing resources are kept until
+// they are evicted, which makes it a gentler way than Reset to
+// rotate credentials.
+func (p *Pool) SetFactory(f func() (Resource, error)) {
+	p.fa.l.Lock()
+	p.f = plain(f)
+	p.fa.l.Unlock()
+}
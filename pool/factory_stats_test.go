@@ -0,0 +1,29 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type failingRes struct{ *tres }
+
+func (s *failingRes) Add() (Resource, error) { return nil, errors.New("down") }
+
+func TestFactoryStats(t *testing.T) {
+	fb := newT()
+	p, err := Initialize(&failingRes{newT()}, Options{PoolSize: 3, Timeout: time.Second,
+		FallbackFactory: fb.Add, FallbackAfter: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := p.FactoryStats()
+	if len(s) != 2 || s[0].Attempts != 3 || s[0].Failures != 3 || s[0].Successes != 0 ||
+		s[1].Attempts != 3 || s[1].Successes != 3 || s[1].Failures != 0 {
+		t.Fatal(s)
+	}
+	p2, _ := Initialize(newT(), Options{PoolSize: 2})
+	if s := p2.FactoryStats(); len(s) != 1 || s[0].Successes != 2 {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,16 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetFactory(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, Lazy: true})
+	a, _ := p.Acquire()
+	p.SetFactory(func() (Resource, error) { r := newT(); r.id = -5; return r, nil })
+	b, _ := p.Acquire()
+	if a.(*tres).id == -5 || b.(*tres).id != -5 {
+		t.Fatal()
+	}
+}
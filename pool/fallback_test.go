@@ -0,0 +1,33 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFallbackFactory(t *testing.T) {
+	var fail atomic.Bool
+	prim := func() (Resource, error) {
+		if fail.Load() {
+			return nil, errors.New("down")
+		}
+		return newT(), nil
+	}
+	fb := func() (Resource, error) { r := newT(); r.id = -1; return r, nil }
+	p, _ := InitializeWith(prim, WithPoolSize(0), WithLazy(), WithFallback(fb, 2, 0))
+	p.o.PoolSize = 10
+	fail.Store(true)
+	if _, err := p.Acquire(); err == nil {
+		t.Fatal("first should fail")
+	}
+	r, err := p.Acquire()
+	if err != nil || r.(*tres).id != -1 || !p.Stats().Fallback {
+		t.Fatal(err)
+	}
+	fail.Store(false)
+	r, _ = p.Acquire()
+	if r.(*tres).id == -1 || p.Stats().Fallback {
+		t.Fatal()
+	}
+}
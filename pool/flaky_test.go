@@ -0,0 +1,24 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFlaky(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond, MaxResourceErrors: 2})
+	a, _ := p.Acquire()
+	p.ReportError(a, errors.New("x"))
+	p.Release(a)
+	a2, _ := p.Acquire()
+	if a2 != a {
+		t.Fatal()
+	}
+	p.ReportError(a, errors.New("x"))
+	p.Release(a)
+	b, _ := p.Acquire()
+	if b == a || !a.(*tres).evicted.Load() {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReleaseFront(t *testing.T) {
+	for _, lifo := range []bool{false, true} {
+		p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: time.Second, LIFO: lifo})
+		a, _ := p.Acquire()
+		b, _ := p.Acquire()
+		p.ReleaseFront(a)
+		p.Release(b)
+		if lifo {
+			// LIFO: b released last goes first anyway
+			if r, _ := p.Acquire(); r != b {
+				t.Fatal()
+			}
+			continue
+		}
+		if r, _ := p.Acquire(); r != a {
+			t.Fatal("front")
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetGeneration(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	a, _ := p.Acquire()
+	p.Reset()
+	b, _ := p.Acquire()
+	if b.(*tres).evicted.Load() {
+		t.Fatal()
+	}
+	p.Release(a)
+	if !a.(*tres).evicted.Load() || p.s != 2 || len(p.meta) != 2 {
+		t.Fatal(p.s, len(p.meta))
+	}
+	p.Release(b)
+	if b.(*tres).evicted.Load() || len(p.idle) != 2 {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,17 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandoff(t *testing.T) {
+	a, _ := Initialize(newT(), Options{PoolSize: 4, Timeout: 10 * time.Millisecond})
+	b, _ := Initialize(newT(), Options{PoolSize: 4, Timeout: 10 * time.Millisecond, Lazy: true})
+	if err := a.MigrateTo(b, 3); err != nil {
+		t.Fatal(err)
+	}
+	if a.Stats().Size != 1 || b.Stats().Size != 3 || b.Stats().Idle != 3 || len(b.meta) != 3 || len(a.meta) != 1 {
+		t.Fatal(a.Stats(), b.Stats())
+	}
+}
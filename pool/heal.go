@@ -0,0 +1,70 @@
+package pool
+
+import "time"
+
+const (
+	healBackoff    = 100 * time.Millisecond // first wait between recovery attempts
+	healMaxBackoff = 30 * time.Second       // longest wait between recovery attempts
+)
+
+// Internal function giving back the room of a resource that could
+// not be created or replaced.
+// If that drains a pool under eviction test, a background routine
+// starts recreating resources.
+// Must be called with the lock held.
+func (p *Pool) shrink() {
+	p.s--
+	if p.s > 0 || !p.o.EvictionTest || p.o.Lazy || p.heal || p.closed {
+		return
+	}
+	p.logf(LogWarn, "pool drained, recovering")
+	p.heal = true
+	go p.recover(true)
+}
+
+// Internal function refilling a drained pool, or one started
+// with fewer than PoolSize resources.
+// Keeps retrying with an exponential backoff until the pool is
+// back to PoolSize, calling Options.OnRecover after the first
+// resource is created if it was drained.
+func (p *Pool) recover(drained bool) {
+	wait := healBackoff
+	recovered := !drained
+	defer func() {
+		p.l.Lock()
+		p.heal = false
+		p.l.Unlock()
+	}()
+	for {
+		p.l.Lock()
+		if p.closed || p.s >= p.o.PoolSize {
+			p.l.Unlock()
+			return
+		}
+		p.s++
+		p.l.Unlock()
+		r, err := p.create(p.ctx, nil)
+		if err != nil {
+			select {
+			case <-time.After(wait):
+			case <-p.done:
+				return
+			}
+			if wait *= 2; wait > healMaxBackoff {
+				wait = healMaxBackoff
+			}
+			continue
+		}
+		if !p.fill(r) {
+			return
+		}
+		wait = healBackoff
+		if !recovered {
+			recovered = true
+			p.logf(LogInfo, "pool recovered")
+			if p.o.OnRecover != nil {
+				p.o.OnRecover()
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRecover(t *testing.T) {
+	var fails atomic.Int64
+	var rec atomic.Bool
+	f := func() (Resource, error) {
+		if fails.Add(-1) >= 0 {
+			return nil, errors.New("down")
+		}
+		r := newT()
+		r.evict = true
+		return r, nil
+	}
+	p, _ := InitializeWith(f, WithPoolSize(2), WithEviction(20*time.Millisecond), WithTimeout(10*time.Millisecond))
+	p.o.OnRecover = func() { rec.Store(true) }
+	fails.Store(1000)
+	time.Sleep(60 * time.Millisecond)
+	if p.Stats().Size != 0 {
+		t.Fatal(p.Stats())
+	}
+	fails.Store(3)
+	time.Sleep(1500 * time.Millisecond)
+	if !rec.Load() || p.Stats().Size == 0 {
+		t.Fatal(p.Stats())
+	}
+	p.Close()
+}
@@ -0,0 +1,36 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type healthRes struct{ *tres }
+
+func (s healthRes) Add() (Resource, error) { r, _ := s.tres.Add(); return healthRes{r.(*tres)}, nil }
+func (s healthRes) MaxConcurrency() int    { return 4 }
+
+func TestOnHealthChange(t *testing.T) {
+	c := make(chan bool, 10)
+	p, _ := Initialize(healthRes{newT()}, Options{PoolSize: 1, Timeout: 20 * time.Millisecond,
+		AcquireOrder: AcquirePingIdle, OnHealthChange: func(r Resource, ok bool) { c <- ok }})
+	r, _ := p.Acquire()
+	r.(healthRes).ping.Store(false)
+	// No longer shared once its Ping failed
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	r.(healthRes).ping.Store(true)
+	p.Release(r)
+	if v, _ := p.Acquire(); v != r {
+		t.Fatal("not recovered")
+	}
+	time.Sleep(10 * time.Millisecond)
+	var got []bool
+	for len(c) > 0 {
+		got = append(got, <-c)
+	}
+	if len(got) != 2 || got[0] || !got[1] {
+		t.Fatal(got)
+	}
+}
@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type hedgeRes struct {
+	*tres
+	n *int64
+}
+
+func (s hedgeRes) Add() (Resource, error) {
+	if atomic.AddInt64(s.n, 1) == 1 {
+		time.Sleep(300 * time.Millisecond)
+	}
+	r, _ := s.tres.Add()
+	return hedgeRes{r.(*tres), s.n}, nil
+}
+
+func TestAcquireHedged(t *testing.T) {
+	p, _ := Initialize(hedgeRes{newT(), new(int64)}, Options{PoolSize: 2, Timeout: time.Second, Lazy: true})
+	s := time.Now()
+	r, err := p.AcquireHedged(20 * time.Millisecond)
+	if err != nil || time.Since(s) > 150*time.Millisecond {
+		t.Fatal(err, time.Since(s))
+	}
+	time.Sleep(400 * time.Millisecond)
+	if st := p.Stats(); st.Size != 1 || st.InUse != 1 {
+		t.Fatal(st)
+	}
+	p.Release(r)
+}
@@ -0,0 +1,18 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond, HistorySize: 3})
+	a, _ := p.Acquire()
+	p.Acquire()
+	p.Release(a)
+	p.Acquire()
+	h := p.History()
+	if len(h) != 3 || h[0].Type != EventTimeout || h[1].Type != EventRelease || h[2].Type != EventAcquire {
+		t.Fatal(h)
+	}
+}
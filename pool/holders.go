@@ -0,0 +1,46 @@
+package pool
+
+import (
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+type (
+	// A resource currently acquired.
+	Holder struct {
+		ID       uint64    // Resource identifier, as in events
+		Resource Resource  // The resource
+		Acquired time.Time // When it was acquired
+		Stack    string    // Stack of the acquiring goroutine, empty unless Options.TrackStacks
+	}
+)
+
+// Resources currently acquired, longest held first.
+// Answers "who is holding all my connections" when the pool runs
+// dry. Capturing stacks on every acquire is costly, so they are
+// only included with Options.TrackStacks.
+func (p *Pool) Holders() []Holder {
+	p.l.RLock()
+	var hs []Holder
+	for _, m := range p.meta {
+		if m.borrowed.IsZero() || m.reclaimed {
+			continue
+		}
+		hs = append(hs, Holder{ID: m.id, Resource: m.r, Acquired: m.borrowed, Stack: m.stack})
+	}
+	p.l.RUnlock()
+	sort.Slice(hs, func(i, j int) bool { return hs[i].Acquired.Before(hs[j].Acquired) })
+	return hs
+}
+
+// Internal function recording the stack of the goroutine that
+// acquired r.
+func (p *Pool) trace(r Resource) {
+	s := string(debug.Stack())
+	p.l.Lock()
+	if m, ok := p.meta[identity(r)]; ok {
+		m.stack = s
+	}
+	p.l.Unlock()
+}
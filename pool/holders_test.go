@@ -0,0 +1,17 @@
+package pool
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHolders(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: time.Second, TrackStacks: true})
+	p.Acquire()
+	p.Acquire()
+	h := p.Holders()
+	if len(h) != 2 || !strings.Contains(h[0].Stack, "TestHolders") {
+		t.Fatal(h)
+	}
+}
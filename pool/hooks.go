@@ -0,0 +1,148 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+)
+
+// Internal functions calling the optional hooks of a resource.
+
+func preAcquire(r Resource) error {
+	if h, ok := r.(PreAcquirer); ok {
+		return h.PreAcquire()
+	}
+	return nil
+}
+
+func postAcquire(r Resource) error {
+	if h, ok := r.(PostAcquirer); ok {
+		return h.PostAcquire()
+	}
+	return nil
+}
+
+func preRelease(r Resource) error {
+	if h, ok := r.(PreReleaser); ok {
+		return h.PreRelease()
+	}
+	return nil
+}
+
+func postRelease(r Resource) error {
+	if h, ok := r.(PostReleaser); ok {
+		return h.PostRelease()
+	}
+	return nil
+}
+
+// Internal function pinging r. A panic counts as a failed Ping
+// unless Options.PropagatePanics is set.
+func (p *Pool) ping(r Resource) (ok bool) {
+	if p.o.OnHealthChange != nil {
+		defer func() {
+			p.flip(r, ok)
+		}()
+	}
+	return p.probe(r)
+}
+
+// Internal function pinging r as ping does, without reporting a
+// change of its health.
+func (p *Pool) probe(r Resource) (ok bool) {
+	if !p.o.PropagatePanics {
+		defer func() {
+			if v := recover(); v != nil {
+				p.logf(LogWarn, "Ping of resource %v panicked: %v", r, v)
+				ok = false
+			}
+		}()
+	}
+	return r.Ping()
+}
+
+// Internal function running a hook of r, marking the goroutine
+// so that pool calls made from within the hook fail with
+// ErrReentrantCall instead of deadlocking.
+func (p *Pool) hook(h func(Resource) error, r Resource) error {
+	switch r.(type) {
+	case PreAcquirer, PostAcquirer, PreReleaser, PostReleaser:
+	default:
+		return nil
+	}
+	id := goid()
+	p.hooks.Add(1)
+	p.hooked.Store(id, true)
+	defer func() {
+		p.hooked.Delete(id)
+		p.hooks.Add(-1)
+	}()
+	return h(r)
+}
+
+// Internal function reporting a change of the health of r to
+// Options.OnHealthChange. Resources start healthy; a failed Ping
+// usually gets them replaced, but a SharedResource that failed
+// one with AcquirePingIdle is only kept from new holders and can
+// recover.
+func (p *Pool) flip(r Resource, healthy bool) {
+	p.l.Lock()
+	changed := p.mark(r, healthy)
+	p.l.Unlock()
+	if changed {
+		p.o.OnHealthChange(r, healthy)
+	}
+}
+
+// Internal function recording the health of r. Returns true if it
+// changed.
+// Must be called with the lock held.
+func (p *Pool) mark(r Resource, healthy bool) bool {
+	m, ok := p.meta[identity(r)]
+	if !ok || m.sick != healthy {
+		return false
+	}
+	m.sick = !healthy
+	return true
+}
+
+// Internal function running a hook of r as hook does, giving up
+// once ctx is done. The hook then carries on in the background.
+func (p *Pool) hookContext(ctx context.Context, h func(Resource) error, r Resource) error {
+	if ctx.Done() == nil {
+		return p.hook(h, r)
+	}
+	c := make(chan error, 1)
+	go func() {
+		c <- p.hook(h, r)
+	}()
+	select {
+	case err := <-c:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Internal function checking if the calling goroutine is running
+// a hook.
+func (p *Pool) reentrant() bool {
+	if p.hooks.Load() == 0 {
+		return false
+	}
+	_, ok := p.hooked.Load(goid())
+	return ok
+}
+
+// Internal function returning the id of the calling goroutine,
+// parsed from the header of its stack trace.
+func goid() uint64 {
+	var b [64]byte
+	s := bytes.TrimPrefix(b[:runtime.Stack(b[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	id, _ := strconv.ParseUint(string(s), 10, 64)
+	return id
+}
@@ -0,0 +1,23 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type mres struct{}
+
+func (m *mres) Add() (Resource, error) { return &mres{}, nil }
+func (m *mres) Ping() bool             { return true }
+func (m *mres) Evict() bool            { return false }
+
+func TestOptionalHooks(t *testing.T) {
+	p, _ := Initialize(&mres{}, Options{PoolSize: 1, Timeout: time.Second})
+	r, err := p.Acquire()
+	if err != nil || p.Release(r) != nil {
+		t.Fatal(err)
+	}
+	if ValidateResource(&mres{}) != nil {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+// Resource held by value with a slice, not usable as a map key.
+type valueRes struct {
+	*tres
+	tags []string
+}
+
+func (v valueRes) Add() (Resource, error) {
+	r, _ := v.tres.Add()
+	return valueRes{r.(*tres), nil}, nil
+}
+
+func TestIncomparableResource(t *testing.T) {
+	v := valueRes{tres: newT()}
+	if _, err := Initialize(v, Options{PoolSize: 2, Timeout: time.Second}); err != ErrIncomparable {
+		t.Fatal(err)
+	}
+	if n := *v.evicts; n != 1 {
+		t.Fatal("not evicted", n)
+	}
+	p, _ := Initialize(v, Options{PoolSize: 2, Timeout: time.Second, Lazy: true})
+	if _, err := p.Acquire(); err != ErrIncomparable || p.Stats().Size != 0 {
+		t.Fatal(err, p.Stats())
+	}
+	if ValidateResource(v) == nil {
+		t.Fatal("validated")
+	}
+}
@@ -0,0 +1,67 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type initRes struct {
+	*tres
+	n *int64
+}
+
+func (s initRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	t := r.(*tres)
+	if atomic.AddInt64(s.n, 1) == 3 {
+		t.ping.Store(false)
+	}
+	return t, nil
+}
+
+func TestValidateOnInit(t *testing.T) {
+	base := newT()
+	_, err := Initialize(initRes{base, new(int64)}, Options{PoolSize: 4, Timeout: time.Second, ValidateOnInit: true})
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if *base.evicts != 3 {
+		t.Fatal(*base.evicts)
+	}
+	if _, err := Initialize(newT(), Options{PoolSize: 4, ValidateOnInit: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failInit struct {
+	*tres
+	n *int64
+}
+
+func (s failInit) Add() (Resource, error) {
+	if atomic.AddInt64(s.n, 1) == 3 {
+		return nil, errors.New("down")
+	}
+	return s.tres.Add()
+}
+
+func TestInitializeCleanup(t *testing.T) {
+	base := newT()
+	if _, err := Initialize(failInit{base, new(int64)}, Options{PoolSize: 4}); err == nil {
+		t.Fatal("no error")
+	}
+	if *base.evicts != 2 {
+		t.Fatal(*base.evicts)
+	}
+	// The factory hands out the same resource twice
+	base = newT()
+	if _, err := Initialize(base, Options{PoolSize: 2, FactoryCtx: func(context.Context) (Resource, error) { return base, nil }}); err != ErrDuplicateResource {
+		t.Fatal(err)
+	}
+	if *base.evicts != 1 {
+		t.Fatal(*base.evicts)
+	}
+}
@@ -0,0 +1,19 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLifetimeJitter(t *testing.T) {
+	tr := newT()
+	p, _ := Initialize(tr, Options{PoolSize: 40, Timeout: time.Second, EvictionTest: true, EvictTestSchedule: 10 * time.Millisecond,
+		MaxLifetime: 300 * time.Millisecond, LifetimeJitter: 250 * time.Millisecond})
+	defer p.Close()
+	time.Sleep(110 * time.Millisecond)
+	n := atomic.LoadInt64(tr.adds) - 40
+	if n <= 0 || n >= 40 {
+		t.Fatal(n)
+	}
+}
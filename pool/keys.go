@@ -0,0 +1,77 @@
+package pool
+
+// Acquire the resource dedicated to key.
+// Every key in Options.PrewarmKeys gets a resource of its own on
+// Initialize, so the first acquire for a known hot key is instant.
+// Keys without a dedicated resource, or whose resource is in use,
+// get any resource as with Acquire.
+func (p *Pool) AcquireWithKey(key string) (Resource, error) {
+	r, _, err := p.checkout(request{timeout: p.EffectiveTimeout(), key: key})
+	return r, err
+}
+
+// Internal function taking the resource dedicated to key, checked
+// with sound as any other idle one is. One that fails is replaced by a
+// resource dedicated to the same key. Returns nil if there is none
+// or it is not idle.
+// Must be called with the lock held.
+func (p *Pool) keyed(key string) Resource {
+	r, ok := p.keys[key]
+	if !ok || key == "" || !p.pick(r) {
+		return nil
+	}
+	if !p.sound(r) {
+		p.logf(LogDebug, "skipping broken resource %v", r)
+		go p.replace(r)
+		return nil
+	}
+	return r
+}
+
+// Internal function dedicating a resource to a key.
+// Does nothing for an empty key or one that already has a resource.
+// Must be called with the lock held.
+func (p *Pool) pin(r Resource, key string) {
+	if key == "" {
+		return
+	}
+	if _, ok := p.keys[key]; ok {
+		return
+	}
+	m, ok := p.meta[identity(r)]
+	if !ok {
+		return
+	}
+	if p.keys == nil {
+		p.keys = make(map[string]Resource)
+	}
+	m.key = key
+	p.keys[key] = r
+}
+
+// Internal function returning the key a resource is dedicated to.
+// Must be called with the lock held.
+func (p *Pool) keyOf(r Resource) string {
+	if m, ok := p.meta[identity(r)]; ok {
+		return m.key
+	}
+	return ""
+}
+
+// Internal function checking if a resource is dedicated to a key
+// and therefore not handed out by a plain acquire.
+// Must be called with the lock held.
+func (p *Pool) dedicated(r Resource) bool {
+	return len(p.keys) > 0 && p.keyOf(r) != ""
+}
+
+// Internal function checking if a resource is idle.
+// Must be called with the lock held.
+func (p *Pool) isIdle(r Resource) bool {
+	for _, v := range p.idle {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireWithKey(t *testing.T) {
+	p, err := Initialize(newT(), Options{PoolSize: 3, Timeout: 20 * time.Millisecond, Lazy: true, PrewarmKeys: []string{"a", "b"}})
+	if err != nil || p.s != 2 {
+		t.Fatal(err)
+	}
+	ra, _ := p.AcquireWithKey("a")
+	rb, _ := p.AcquireWithKey("b")
+	if ra != p.keys["a"] || rb != p.keys["b"] {
+		t.Fatal()
+	}
+	p.Release(ra)
+	g, _ := p.Acquire()
+	if g == ra {
+		t.Fatal("dedicated given out")
+	}
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	r2, _ := p.AcquireWithKey("a")
+	if r2 != ra {
+		t.Fatal()
+	}
+}
+
+func TestAcquireWithKeyTaken(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, PrewarmKeys: []string{"a"}})
+	ra, _ := p.AcquireSpecific(p.keys["a"])
+	// The dedicated resource is held, any other one will do
+	start := time.Now()
+	r, err := p.AcquireWithKey("a")
+	if err != nil || r == ra || time.Since(start) > 100*time.Millisecond {
+		t.Fatal(err, time.Since(start))
+	}
+}
+
+func TestAcquireWithKeyBlocked(t *testing.T) {
+	if _, err := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, PrewarmKeys: []string{"a"}}); err == nil {
+		t.Fatal("every resource dedicated")
+	}
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: 300 * time.Millisecond, PrewarmKeys: []string{"a"}})
+	ra, _ := p.AcquireWithKey("a")
+	rb, _ := p.Acquire()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.Release(ra)
+	}()
+	start := time.Now()
+	r, err := p.AcquireWithKey("a")
+	if err != nil || r != ra || time.Since(start) > 200*time.Millisecond {
+		t.Fatal(err, time.Since(start))
+	}
+	p.Release(rb)
+}
+
+func TestAcquireWithKeyRetired(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, PrewarmKeys: []string{"a"}})
+	key := func() Resource {
+		p.l.RLock()
+		defer p.l.RUnlock()
+		return p.keys["a"]
+	}
+	ra := key()
+	p.Retire(ra)
+	r, err := p.AcquireWithKey("a")
+	if err != nil || r == ra {
+		t.Fatal("retired resource lent", err)
+	}
+	p.Release(r)
+	time.Sleep(10 * time.Millisecond)
+	// Replaced by a resource dedicated to the same key
+	if !ra.(*tres).evicted.Load() || key() == ra {
+		t.Fatal("not replaced")
+	}
+	r, err = p.AcquireWithKey("a")
+	if err != nil || r != key() {
+		t.Fatal(err)
+	}
+	p.Retire(r)
+	p.Release(r)
+	p.Retire(key())
+	if _, err := p.AcquireSpecific(key()); err != ErrUnknownResource {
+		t.Fatal(err)
+	}
+}
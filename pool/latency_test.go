@@ -0,0 +1,13 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreationLatency(t *testing.T) {
+	p, _ := InitializeWith(func() (Resource, error) { time.Sleep(20 * time.Millisecond); return newT(), nil }, WithPoolSize(2))
+	if l := p.CreationLatency(); l < 20*time.Millisecond || l > 40*time.Millisecond {
+		t.Fatal(l)
+	}
+}
@@ -0,0 +1,109 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type slowAdd struct{ *tres }
+
+func (s *slowAdd) Add() (Resource, error) {
+	if atomic.LoadInt64(s.adds) > 0 {
+		time.Sleep(50 * time.Millisecond)
+	}
+	r, _ := s.tres.Add()
+	return &slowAdd{r.(*tres)}, nil
+}
+
+func TestLazyCreations(t *testing.T) {
+	tr := newT()
+	p, _ := Initialize(&slowAdd{tr}, Options{PoolSize: 10, Timeout: time.Second, Lazy: true})
+	h, _ := p.Acquire()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r, err := p.Acquire()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		time.Sleep(60 * time.Millisecond)
+		p.Release(r)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	p.Release(h)
+	time.Sleep(10 * time.Millisecond)
+	// Demand of two at most: the creation in flight serves this one
+	r, err := p.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	p.Release(r)
+	if n := atomic.LoadInt64(tr.adds); n != 2 {
+		t.Fatal(n)
+	}
+	// A burst on a drained lazy pool creates only up to the deficit
+	tr2 := newT()
+	p2, _ := Initialize(tr2, Options{PoolSize: 4, Timeout: time.Second, Lazy: true})
+	var held sync.Mutex
+	var rs []Resource
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := p2.Acquire()
+			if err != nil {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+			held.Lock()
+			rs = append(rs, r)
+			held.Unlock()
+			p2.Release(r)
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt64(tr2.adds); n > 4 || len(rs) != 20 {
+		t.Fatal(n, len(rs))
+	}
+}
+
+func TestLazyDeficit(t *testing.T) {
+	tr := newT()
+	f := func() (Resource, error) {
+		time.Sleep(10 * time.Millisecond)
+		return tr.Add()
+	}
+	p, _ := InitializeWith(f, WithPoolSize(6), WithLazy(), WithTimeout(time.Second))
+	a, _ := p.Acquire()
+	b, _ := p.Acquire()
+	p.Release(a)
+	p.Release(b)
+	before := atomic.LoadInt64(tr.adds)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			r, err := p.Acquire()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(30 * time.Millisecond)
+			p.Release(r)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	// Two idle of six, every other one is created exactly once
+	if n := atomic.LoadInt64(tr.adds) - before; n != 4 || p.Stats().Size != 6 {
+		t.Fatal(n, p.Stats())
+	}
+}
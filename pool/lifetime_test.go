@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolMaxLifetime(t *testing.T) {
+	c := make(chan bool, 1)
+	tr := newT()
+	p, _ := Initialize(tr, Options{PoolSize: 2, Timeout: time.Second, PoolMaxLifetime: 30 * time.Millisecond,
+		OnClose: func(expired bool) { c <- expired }})
+	r, _ := p.Acquire()
+	select {
+	case e := <-c:
+		if !e {
+			t.Fatal(e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not closed")
+	}
+	if _, err := p.Acquire(); err != ErrPoolClosed || *tr.evicts != 1 {
+		t.Fatal(err, *tr.evicts)
+	}
+	p.Release(r)
+	if *tr.evicts != 2 {
+		t.Fatal(*tr.evicts)
+	}
+	p2, _ := Initialize(newT(), Options{PoolSize: 1, OnClose: func(expired bool) { c <- expired }})
+	p2.Close()
+	if <-c {
+		t.Fatal("expired")
+	}
+}
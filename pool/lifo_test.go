@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLIFO(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 8, Timeout: time.Second, LIFO: true})
+	a, _ := p.Acquire()
+	p.Release(a)
+	b, _ := p.Acquire()
+	if a != b {
+		t.Fatal()
+	}
+	p.Release(b)
+	stack(t, p)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				r, err := p.Acquire()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				p.Release(r)
+			}
+		}()
+	}
+	wg.Wait()
+	seen := map[Resource]bool{}
+	for _, r := range p.idle {
+		if seen[r] {
+			t.Fatal("dup")
+		}
+		seen[r] = true
+	}
+	if len(seen) != 8 {
+		t.Fatal(len(seen))
+	}
+	stack(t, p)
+}
+
+// Check that resources come back in the reverse order of release.
+func stack(t *testing.T, p *Pool) {
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	for _, r := range rs {
+		p.Release(r)
+	}
+	for i := 2; i >= 0; i-- {
+		if r, _ := p.Acquire(); r != rs[i] {
+			t.Fatal("not last released first", i)
+		}
+	}
+	for _, r := range rs {
+		p.Release(r)
+	}
+}
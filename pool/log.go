@@ -0,0 +1,40 @@
+package pool
+
+import "fmt"
+
+type (
+	// Minimal logging interface, satisfied by *log.Logger.
+	Logger interface {
+		Printf(format string, v ...interface{})
+	}
+	// Severity of a pool log message.
+	LogLevel int
+)
+
+const (
+	LogDebug LogLevel = iota // Routine events such as evictions
+	LogInfo                  // Noteworthy events
+	LogWarn                  // Failures such as timeouts and failed creations
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Internal function for logging.
+// Does nothing unless Options.Logger is set and the level is
+// at least Options.LogLevel.
+func (p *Pool) logf(l LogLevel, format string, v ...interface{}) {
+	if p.o.Logger == nil || l < p.o.LogLevel {
+		return
+	}
+	p.o.Logger.Printf("pool: "+l.String()+": "+format, v...)
+}
@@ -0,0 +1,53 @@
+package pool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type logger struct {
+	l sync.Mutex
+	v []string
+}
+
+func (g *logger) Printf(format string, v ...interface{}) {
+	g.l.Lock()
+	g.v = append(g.v, fmt.Sprintf(format, v...))
+	g.l.Unlock()
+}
+
+func (g *logger) lines() []string {
+	g.l.Lock()
+	defer g.l.Unlock()
+	return append([]string(nil), g.v...)
+}
+
+func TestLogf(t *testing.T) {
+	g := &logger{}
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond, Logger: g, LogLevel: LogWarn})
+	r, _ := p.Acquire()
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	p.Release(r)
+	v := g.lines()
+	if len(v) != 1 || !strings.HasPrefix(v[0], "pool: warn: acquire timed out") {
+		t.Fatal(v)
+	}
+	// Evictions are logged at debug level, below LogWarn
+	g = &logger{}
+	r2 := newT()
+	r2.evict = true
+	p, _ = Initialize(r2, Options{PoolSize: 1, Logger: g})
+	p.refreshPool(nil)
+	v = g.lines()
+	if len(v) != 1 || !strings.HasPrefix(v[0], "pool: debug: evicted resource") {
+		t.Fatal(v)
+	}
+	if LogInfo.String() != "info" || LogLevel(7).String() != "level(7)" {
+		t.Fatal(LogInfo, LogLevel(7))
+	}
+}
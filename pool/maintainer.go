@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Background routine refreshing several pools on one schedule,
+	// e.g. many small pools to the same backend, instead of a
+	// routine per pool. Pools opt in with Options.Maintainer; their
+	// Options.EvictTestSchedule is then ignored.
+	Maintainer struct {
+		every time.Duration  // time between refresh passes
+		pools map[*Pool]bool // registered pools
+		wake  chan struct{}  // a pool asked for a refresh, see TriggerRefresh
+		done  chan struct{}  // closed on Stop
+		stop  sync.Once      // closes done once
+		next  time.Time      // next scheduled refresh
+		l     sync.Mutex     // guards pools and next
+	}
+)
+
+// Create a Maintainer refreshing its pools every interval.
+// Pools refreshed are those with Options.EvictionTest set, others
+// only when asked for with TriggerRefresh.
+//
+// Usage:
+//
+//	m := pool.NewMaintainer(time.Minute)
+//	defer m.Stop()
+//	a, _ := pool.Initialize(r, pool.Options{PoolSize: 2, EvictionTest: true, Maintainer: m})
+//	b, _ := pool.Initialize(r, pool.Options{PoolSize: 2, EvictionTest: true, Maintainer: m})
+func NewMaintainer(every time.Duration) *Maintainer {
+	m := &Maintainer{
+		every: every,
+		pools: make(map[*Pool]bool),
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	m.next = time.Now().Add(every)
+	go m.run()
+	return m
+}
+
+// Stop the background routine. Registered pools are no longer
+// refreshed.
+func (m *Maintainer) Stop() {
+	m.stop.Do(func() {
+		close(m.done)
+	})
+}
+
+// Internal function registering a pool.
+func (m *Maintainer) add(p *Pool) {
+	m.l.Lock()
+	m.pools[p] = true
+	m.l.Unlock()
+}
+
+// Internal function unregistering a closed pool.
+func (m *Maintainer) remove(p *Pool) {
+	m.l.Lock()
+	delete(m.pools, p)
+	m.l.Unlock()
+}
+
+// Internal function waking the routine up for a pool that asked
+// for a refresh.
+func (m *Maintainer) poke() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Internal function refreshing the pools, all of them on the
+// schedule and those that asked for it in between.
+func (m *Maintainer) run() {
+	t := time.NewTicker(m.every)
+	defer t.Stop()
+	for {
+		tick := false
+		select {
+		case <-t.C:
+			tick = true
+			m.l.Lock()
+			m.next = time.Now().Add(m.every)
+			m.l.Unlock()
+		case <-m.wake:
+		case <-m.done:
+			return
+		}
+		m.l.Lock()
+		pools := make([]*Pool, 0, len(m.pools))
+		for p := range m.pools {
+			pools = append(pools, p)
+		}
+		m.l.Unlock()
+		for _, p := range pools {
+			due := tick && p.o.EvictionTest && !p.skipped()
+			select {
+			case <-p.trigger:
+				due = true
+			default:
+			}
+			if due {
+				p.refresh()
+			}
+		}
+	}
+}
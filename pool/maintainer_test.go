@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaintainer(t *testing.T) {
+	base := runtime.NumGoroutine()
+	m := NewMaintainer(20 * time.Millisecond)
+	defer m.Stop()
+	before := runtime.NumGoroutine()
+	var ts []*tres
+	var ps []*Pool
+	for i := 0; i < 5; i++ {
+		tr := newT()
+		p, _ := Initialize(tr, Options{PoolSize: 2, Timeout: time.Second, EvictionTest: true, EvictTestSchedule: time.Hour, Maintainer: m})
+		ts = append(ts, tr)
+		ps = append(ps, p)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatal(before, n)
+	}
+	time.Sleep(100 * time.Millisecond)
+	for i, tr := range ts {
+		if atomic.LoadInt64(tr.evicts) == 0 {
+			t.Fatal(i)
+		}
+	}
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Maintainer: m})
+	tr := newT()
+	n := NewMaintainer(time.Hour)
+	defer n.Stop()
+	q, _ := Initialize(tr, Options{PoolSize: 1, Maintainer: n})
+	q.TriggerRefresh()
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt64(tr.evicts) != 1 {
+		t.Fatal(*tr.evicts)
+	}
+	// A stopped Maintainer no longer refreshes its pools
+	n.Stop()
+	q.TriggerRefresh()
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt64(tr.evicts) != 1 {
+		t.Fatal("refreshed after Stop", *tr.evicts)
+	}
+	q.Close()
+	p.Close()
+	for _, p := range ps {
+		p.Close()
+	}
+	m.l.Lock()
+	if len(m.pools) != 0 {
+		t.Fatal(m.pools)
+	}
+	m.l.Unlock()
+	// Both routines exit once stopped
+	m.Stop()
+	for end := time.Now().Add(time.Second); runtime.NumGoroutine() > base; time.Sleep(time.Millisecond) {
+		if time.Now().After(end) {
+			t.Fatal("goroutines left", base, runtime.NumGoroutine())
+		}
+	}
+}
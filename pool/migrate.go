@@ -0,0 +1,162 @@
+package pool
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Move up to n idle resources to dst.
+// Only as many resources as dst has room for are moved, so dst
+// never grows past its PoolSize. Both pools must hold resources
+// of the same kind; that is up to the caller. Useful to rebalance
+// between a shrinking and a growing pool without evicting and
+// recreating resources.
+func (p *Pool) MigrateTo(dst *Pool, n int) error {
+	if dst == p {
+		return errors.New("Can not migrate to the same pool")
+	}
+	if n <= 0 {
+		return nil
+	}
+	// Reserve room in dst first so the locks are never held together
+	dst.l.Lock()
+	if dst.closed {
+		dst.l.Unlock()
+		return ErrPoolClosed
+	}
+	room := dst.o.PoolSize - dst.s
+	if room > int64(n) {
+		room = int64(n)
+	}
+	if room <= 0 {
+		dst.l.Unlock()
+		return nil
+	}
+	dst.s += room
+	dst.l.Unlock()
+
+	p.l.Lock()
+	var moved []*record
+	var rs []Resource
+	if !p.closed {
+		for int64(len(rs)) < room {
+			r := p.take()
+			if r == nil {
+				break
+			}
+			moved = append(moved, p.meta[identity(r)])
+			delete(p.meta, identity(r))
+			rs = append(rs, r)
+		}
+		p.s -= int64(len(rs))
+	}
+	p.l.Unlock()
+
+	dst.l.Lock()
+	defer dst.l.Unlock()
+	dst.s -= room - int64(len(rs))
+	for i, r := range rs {
+		if dst.closed {
+			dst.s--
+			dst.evict(r)
+			continue
+		}
+		dst.adopt(r, moved[i])
+		dst.put(r)
+	}
+	p.logf(LogDebug, "migrated %d resources", len(rs))
+	return nil
+}
+
+// Release r, acquired from p, into dst instead.
+// dst takes over the resource and its history, growing by one
+// while p shrinks by one. Fails with ErrPoolFull if dst has no
+// room left and if r is not of the kind dst holds, leaving r with
+// the caller before any release hook runs. Resources of
+// AcquireOrFallback, Options.OverflowPool and SharedResource ones
+// can not move. Supports rebalancing between sibling pools.
+func (p *Pool) ReleaseTo(dst *Pool, r Resource) error {
+	if dst == p {
+		return p.Release(r)
+	}
+	if p.reentrant() {
+		return ErrReentrantCall
+	}
+	if p.reclaimed(r) {
+		return ErrReclaimed
+	}
+	p.l.Lock()
+	m, ok := p.meta[identity(r)]
+	lent := ok && !m.borrowed.IsZero()
+	fixed := p.spare[r] || p.spill[r] || ok && m.shares > 0
+	p.l.Unlock()
+	if fixed {
+		// Kept track of by this pool only
+		return errors.New("Spare, spilled or shared resources can not move to another pool")
+	}
+	if !lent {
+		return ErrUnknownResource
+	}
+	// Check dst before the hooks, the caller keeps r if it fails
+	dst.l.Lock()
+	if dst.closed {
+		dst.l.Unlock()
+		return ErrPoolClosed
+	}
+	if !dst.compatible(r) {
+		dst.l.Unlock()
+		return errors.New("Resource of a different kind than the destination pool")
+	}
+	if dst.s >= dst.o.PoolSize {
+		dst.l.Unlock()
+		return ErrPoolFull
+	}
+	dst.s++
+	dst.l.Unlock()
+	if err := p.hook(preRelease, r); err != nil {
+		dst.l.Lock()
+		dst.s--
+		dst.l.Unlock()
+		return err
+	}
+
+	p.l.Lock()
+	m, ok = p.meta[identity(r)]
+	if ok && !m.borrowed.IsZero() {
+		p.emit(EventRelease, r, p.held(r))
+		if m.key != "" && p.keys[m.key] == r {
+			delete(p.keys, m.key)
+		}
+		delete(p.meta, identity(r))
+		p.s--
+	}
+	p.l.Unlock()
+	if !ok || m.borrowed.IsZero() {
+		dst.l.Lock()
+		dst.s--
+		dst.l.Unlock()
+		return ErrUnknownResource
+	}
+
+	dst.l.Lock()
+	if dst.closed {
+		dst.s--
+		dst.l.Unlock()
+		dst.evict(r)
+		return ErrPoolClosed
+	}
+	dst.adopt(r, m)
+	dst.put(r)
+	dst.l.Unlock()
+	return p.hook(postRelease, r)
+}
+
+// Internal function checking if r is of the same type as the
+// resources of the pool. Any resource fits an empty pool.
+// Must be called with the lock held.
+func (p *Pool) compatible(r Resource) bool {
+	for _, m := range p.meta {
+		return reflect.TypeOf(m.r) == reflect.TypeOf(r)
+	}
+	return true
+}
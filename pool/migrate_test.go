@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReleaseTo(t *testing.T) {
+	a, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	b, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, Lazy: true})
+	r, _ := a.Acquire()
+	if err := a.ReleaseTo(b, r); err != nil {
+		t.Fatal(err)
+	}
+	if a.Stats().Size != 1 || b.Stats().Size != 1 || b.Stats().Idle != 1 || !b.Owns(r) || a.Owns(r) {
+		t.Fatal(a.Stats(), b.Stats())
+	}
+	c, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second})
+	r, _ = a.Acquire()
+	if err := a.ReleaseTo(c, r); err != ErrPoolFull {
+		t.Fatal(err)
+	}
+	if err := a.ReleaseTo(b, r); err != nil {
+		t.Fatal(err)
+	}
+	type other struct{ *tres }
+	if err := c.ReleaseTo(b, other{newT()}); err == nil {
+		t.Fatal("incompatible accepted")
+	}
+	r, _ = b.Acquire()
+	if err := b.ReleaseTo(a, r); err != nil || a.Stats().Size != 1 || b.Stats().Size != 1 {
+		t.Fatal(err, a.Stats(), b.Stats())
+	}
+	// The release hooks run once, by the Release that follows a failure
+	h := &reservedRes{newT(), new(int64), new(int64)}
+	d, _ := Initialize(h, Options{PoolSize: 1, Timeout: time.Second})
+	e, _ := Initialize(h, Options{PoolSize: 1, Timeout: time.Second})
+	r, _ = d.Acquire()
+	if err := d.ReleaseTo(e, r); err != ErrPoolFull || atomic.LoadInt64(h.rel) != 0 {
+		t.Fatal(err, atomic.LoadInt64(h.rel))
+	}
+	d.Release(r)
+	if atomic.LoadInt64(h.rel) != 1 {
+		t.Fatal(atomic.LoadInt64(h.rel))
+	}
+	// Shared resources stay with their pool
+	s, _ := Initialize(sharedRes{newT()}, Options{PoolSize: 1, Timeout: time.Second})
+	u, _ := Initialize(sharedRes{newT()}, Options{PoolSize: 1, Timeout: time.Second, Lazy: true})
+	r, _ = s.Acquire()
+	if err := s.ReleaseTo(u, r); err == nil || u.Stats().Size != 0 {
+		t.Fatal(err)
+	}
+	// And so do fallback ones
+	d.o.Timeout = 10 * time.Millisecond
+	d.Acquire()
+	f, used, _ := d.AcquireOrFallback(func() (Resource, error) { return h.Add() })
+	e.o.PoolSize = 2
+	if err := d.ReleaseTo(e, f); !used || err == nil || e.Stats().Size != 1 {
+		t.Fatal(used, err)
+	}
+}
@@ -0,0 +1,43 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type partialRes struct {
+	*tres
+	up *atomic.Bool
+}
+
+func (s *partialRes) Add() (Resource, error) {
+	if atomic.LoadInt64(s.adds) >= 2 && !s.up.Load() {
+		return nil, errors.New("down")
+	}
+	r, _ := s.tres.Add()
+	return &partialRes{r.(*tres), s.up}, nil
+}
+
+func TestMinInitSize(t *testing.T) {
+	up := new(atomic.Bool)
+	if _, err := Initialize(&partialRes{newT(), up}, Options{PoolSize: 4}); err == nil {
+		t.Fatal("initialized")
+	}
+	p, err := Initialize(&partialRes{newT(), up}, Options{PoolSize: 4, MinInitSize: 2, Timeout: time.Second})
+	if err != nil || p.Stats().Size != 2 {
+		t.Fatal(err, p.Stats())
+	}
+	r, err := p.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Release(r)
+	up.Store(true)
+	time.Sleep(500 * time.Millisecond)
+	if s := p.Stats(); s.Size != 4 || s.Idle != 4 {
+		t.Fatal(s)
+	}
+	p.Close()
+}
@@ -0,0 +1,72 @@
+package pool
+
+import (
+	"errors"
+	"sync"
+)
+
+type (
+	// Pools behind one handle, e.g. one per shard of a backend.
+	// A routing function picks the pool for a key.
+	MultiPool struct {
+		pools map[string]*Pool        // pools by name
+		route func(key string) string // name of the pool for a key
+		held  map[Resource]*Pool      // pool acquired resources come from
+		l     sync.Mutex              // guards held
+	}
+)
+
+// Create a MultiPool over the named pools.
+// route maps the key given to Acquire to the name of a pool.
+//
+// Usage:
+//
+//	m := pool.NewMultiPool(map[string]*pool.Pool{"a": a, "b": b},
+//		func(key string) string { return shard(key) })
+func NewMultiPool(pools map[string]*Pool, route func(key string) string) *MultiPool {
+	return &MultiPool{pools: pools, route: route, held: make(map[Resource]*Pool)}
+}
+
+// Acquire a resource from the pool key routes to.
+// Returns ErrUnknownPool if there is no pool by that name.
+func (m *MultiPool) Acquire(key string) (Resource, error) {
+	p, ok := m.pools[m.route(key)]
+	if !ok {
+		return nil, ErrUnknownPool
+	}
+	r, err := p.Acquire()
+	if r == nil {
+		return nil, err
+	}
+	// With PostAcquireReturnResource r comes along with an error
+	m.l.Lock()
+	m.held[r] = p
+	m.l.Unlock()
+	return r, err
+}
+
+// Release a resource to the pool it was acquired from.
+func (m *MultiPool) Release(r Resource) error {
+	m.l.Lock()
+	p, ok := m.held[r]
+	delete(m.held, r)
+	m.l.Unlock()
+	if !ok {
+		return ErrUnknownResource
+	}
+	return p.Release(r)
+}
+
+// Pool with the given name, nil if there is none.
+func (m *MultiPool) Pool(name string) *Pool {
+	return m.pools[name]
+}
+
+// Close every pool.
+func (m *MultiPool) Close() error {
+	var errs []error
+	for _, p := range m.pools {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
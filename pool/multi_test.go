@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMultiPool(t *testing.T) {
+	a, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	b, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	m := NewMultiPool(map[string]*Pool{"a": a, "b": b}, func(k string) string { return k[:1] })
+	r, err := m.Acquire("alpha")
+	if err != nil || a.Stats().InUse != 1 || b.Stats().InUse != 0 {
+		t.Fatal(err)
+	}
+	r2, _ := m.Acquire("beta")
+	if b.Stats().InUse != 1 {
+		t.Fatal()
+	}
+	m.Release(r)
+	m.Release(r2)
+	if a.Stats().InUse != 0 || b.Stats().InUse != 0 {
+		t.Fatal()
+	}
+	if _, err := m.Acquire("zeta"); err != ErrUnknownPool {
+		t.Fatal(err)
+	}
+	if m.Release(r) != ErrUnknownResource {
+		t.Fatal()
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
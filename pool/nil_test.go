@@ -0,0 +1,20 @@
+package pool
+
+import (
+	"testing"
+)
+
+func TestNilResource(t *testing.T) {
+	_, err := InitializeWith(func() (Resource, error) { return nil, nil }, WithPoolSize(2))
+	if err != ErrNilResource {
+		t.Fatal(err)
+	}
+	_, err = InitializeWith(func() (Resource, error) { var x *tres; return x, nil }, WithPoolSize(2))
+	if err != ErrNilResource {
+		t.Fatal(err)
+	}
+	p, _ := InitializeWith(func() (Resource, error) { return nil, nil }, WithPoolSize(2), WithLazy())
+	if _, err := p.Acquire(); err != ErrNilResource {
+		t.Fatal(err)
+	}
+}
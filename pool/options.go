@@ -0,0 +1,113 @@
+package pool
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Functional option for InitializeWith.
+	Option func(*Options)
+)
+
+const (
+	DefaultPoolSize = 10          // Pool size used by InitializeWith
+	DefaultTimeout  = time.Second // Acquire timeout used by InitializeWith
+)
+
+// Initialize a pool from a factory and functional options.
+// Only the options that differ from the defaults need to be
+// given; the pool holds DefaultPoolSize resources and times
+// out acquires after DefaultTimeout unless told otherwise.
+//
+// Usage:
+//
+//	p, err := pool.InitializeWith(newConn,
+//		pool.WithPoolSize(20),
+//		pool.WithEviction(time.Minute))
+func InitializeWith(factory func() (Resource, error), opts ...Option) (*Pool, error) {
+	o := Options{
+		PoolSize: DefaultPoolSize,
+		Timeout:  DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return initialize(context.Background(), factory, o, nil)
+}
+
+// Set the number of resources in the pool.
+func WithPoolSize(n int64) Option {
+	return func(o *Options) {
+		o.PoolSize = n
+	}
+}
+
+// Set the timeout for acquiring a resource.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// Test resources for eviction on the given schedule.
+func WithEviction(schedule time.Duration) Option {
+	return func(o *Options) {
+		o.EvictionTest = true
+		o.EvictTestSchedule = schedule
+	}
+}
+
+// Set what Release does after the pool is closed.
+func WithReleaseAfterClose(m CloseMode) Option {
+	return func(o *Options) {
+		o.ReleaseAfterClose = m
+	}
+}
+
+// Create resources on demand instead of on Initialize.
+func WithLazy() Option {
+	return func(o *Options) {
+		o.Lazy = true
+	}
+}
+
+// Log messages of at least the given level to l.
+func WithLogger(l Logger, level LogLevel) Option {
+	return func(o *Options) {
+		o.Logger = l
+		o.LogLevel = level
+	}
+}
+
+// Hand out the most recently released resource first.
+func WithLIFO() Option {
+	return func(o *Options) {
+		o.LIFO = true
+	}
+}
+
+// Ping resources on Release, replacing dead ones.
+func WithTestOnReturn() Option {
+	return func(o *Options) {
+		o.TestOnReturn = true
+	}
+}
+
+// Fall back to f after the factory failed after times in a row.
+func WithFallback(f func() (Resource, error), after int, retry time.Duration) Option {
+	return func(o *Options) {
+		o.FallbackFactory = f
+		o.FallbackAfter = after
+		o.FallbackRetry = retry
+	}
+}
+
+// Create up to n resources beyond the pool size when none is idle,
+// giving up on a creation after timeout (0 waits for it).
+func WithOverflow(n int64, timeout time.Duration) Option {
+	return func(o *Options) {
+		o.MaxOverflow = n
+		o.OverflowTimeout = timeout
+	}
+}
@@ -0,0 +1,14 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	r := newT()
+	p, err := InitializeWith(r.Add, WithLazy(), WithTimeout(time.Millisecond))
+	if err != nil || p.o.PoolSize != DefaultPoolSize || p.o.Timeout != time.Millisecond || !p.o.Lazy || p.s != 0 {
+		t.Fatal(p.o)
+	}
+}
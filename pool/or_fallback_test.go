@@ -0,0 +1,25 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireOrFallback(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond})
+	a, _ := p.Acquire()
+	fb := newT()
+	r, used, err := p.AcquireOrFallback(func() (Resource, error) { return fb, nil })
+	if err != nil || !used || r != fb {
+		t.Fatal(err, used)
+	}
+	p.Release(r)
+	if !fb.evicted.Load() || p.Stats().Size != 1 || p.Stats().Idle != 0 {
+		t.Fatal(p.Stats())
+	}
+	p.Release(a)
+	r, used, _ = p.AcquireOrFallback(func() (Resource, error) { return fb, nil })
+	if used || r != a {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,79 @@
+package pool
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type orderRes struct {
+	*tres
+	l    *sync.Mutex
+	log  *[]string
+	fail *bool
+}
+
+func (s *orderRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &orderRes{r.(*tres), s.l, s.log, s.fail}, nil
+}
+func (s *orderRes) Ping() bool {
+	s.l.Lock()
+	*s.log = append(*s.log, "ping")
+	s.l.Unlock()
+	return s.tres.Ping()
+}
+func (s *orderRes) PreAcquire() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	*s.log = append(*s.log, "pre")
+	if *s.fail {
+		return errors.New("pre")
+	}
+	return nil
+}
+
+func TestAcquireOrder(t *testing.T) {
+	for o, want := range map[AcquireOrder][]string{
+		AcquireUnchecked:    {"pre"},
+		AcquirePingIdle:     {"ping", "pre"},
+		AcquirePingTaken:    {"ping", "pre"},
+		AcquirePingPrepared: {"pre", "ping"},
+	} {
+		log := []string{}
+		f := &orderRes{newT(), new(sync.Mutex), &log, new(bool)}
+		p, _ := Initialize(f, Options{PoolSize: 2, Timeout: time.Second, AcquireOrder: o, LIFO: true})
+		r, err := p.Acquire()
+		if err != nil || !reflect.DeepEqual(log, want) {
+			t.Fatal(o, err, log)
+		}
+		p.Release(r)
+		if o == AcquireUnchecked {
+			continue
+		}
+		r.(*orderRes).ping.Store(false)
+		log = log[:0]
+		n, err := p.Acquire()
+		if err != nil || n == r {
+			t.Fatal(o, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if !r.(*orderRes).evicted.Load() {
+			t.Fatal(o, "dead kept")
+		}
+		p.Release(n)
+	}
+	log := []string{}
+	f := &orderRes{newT(), new(sync.Mutex), &log, new(bool)}
+	p, _ := Initialize(f, Options{PoolSize: 2, Timeout: time.Second})
+	*f.fail = true
+	if _, err := p.Acquire(); err == nil {
+		t.Fatal("no error")
+	}
+	*f.fail = false
+	if s := p.Stats(); s.Idle != 2 || s.Size != 2 {
+		t.Fatal(s)
+	}
+}
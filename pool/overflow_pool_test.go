@@ -0,0 +1,47 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverflowPool(t *testing.T) {
+	s, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 20 * time.Millisecond, OverflowPool: s})
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, err := p.Acquire()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		rs = append(rs, r)
+	}
+	if st := s.Stats(); st.InUse != 2 {
+		t.Fatal(st)
+	}
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	for _, r := range rs {
+		if err := p.Release(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.Stats().Idle != 1 || s.Stats().Idle != 2 {
+		t.Fatal(p.Stats(), s.Stats())
+	}
+}
+
+func TestOverflowPoolPostAcquire(t *testing.T) {
+	n := int64(1)
+	s, _ := Initialize(&postRes{newT(), &n}, Options{PoolSize: 1, OnPostAcquireFailure: PostAcquireReturnResource})
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 20 * time.Millisecond, OverflowPool: s})
+	r, _ := p.Acquire()
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	if st := s.Stats(); st.Idle != 1 {
+		t.Fatal(st)
+	}
+	p.Release(r)
+}
@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverflowTimeout(t *testing.T) {
+	slow := false
+	f := func() (Resource, error) {
+		if slow {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return newT(), nil
+	}
+	p, _ := InitializeWith(f, WithPoolSize(1), WithTimeout(time.Second))
+	p.o.MaxOverflow = 2
+	p.o.OverflowTimeout = 20 * time.Millisecond
+	p.Acquire()
+	_, err := p.Acquire()
+	if err != nil || p.Stats().Size != 2 {
+		t.Fatal(err)
+	}
+	slow = true
+	st := time.Now()
+	_, err = p.Acquire()
+	if err != ErrOverflowTimeout || time.Since(st) > 60*time.Millisecond {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if p.Stats().Idle != 1 {
+		t.Fatal(p.Stats())
+	}
+}
@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwns(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	q, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second})
+	r, _ := p.Acquire()
+	s, _ := q.Acquire()
+	if !p.Owns(r) || p.Owns(s) || !q.Owns(s) || q.Owns(r) || p.Owns(newT()) {
+		t.Fatal()
+	}
+	// Released into the wrong pool
+	if err := p.Release(s); err != ErrUnknownResource {
+		t.Fatal(err)
+	}
+	if st := p.Stats(); st.Size != 2 || st.Idle != 1 {
+		t.Fatal(st)
+	}
+	if err := q.Release(s); err != nil {
+		t.Fatal(err)
+	}
+}
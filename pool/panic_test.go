@@ -0,0 +1,29 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type panicRes struct{ *tres }
+
+func (s panicRes) Add() (Resource, error) { r, _ := s.tres.Add(); return panicRes{r.(*tres)}, nil }
+func (s panicRes) Ping() bool {
+	if !s.tres.ping.Load() {
+		panic("nil client")
+	}
+	return true
+}
+
+func TestPingPanic(t *testing.T) {
+	p, _ := Initialize(panicRes{newT()}, Options{PoolSize: 1, Timeout: time.Second, TestOnReturn: true})
+	r, _ := p.Acquire()
+	r.(panicRes).ping.Store(false)
+	if err := p.Release(r); err != nil {
+		t.Fatal(err)
+	}
+	n, err := p.Acquire()
+	if err != nil || n == r || !r.(panicRes).evicted.Load() {
+		t.Fatal(err)
+	}
+}
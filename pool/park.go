@@ -0,0 +1,34 @@
+package pool
+
+// Hold every idle resource aside without evicting it.
+// Until Unpark the pool reports no idle resources, released ones
+// are held aside as well and acquires wait or time out; the pool
+// does not grow either. Used to pause traffic during a planned
+// backend maintenance while keeping the connections.
+func (p *Pool) Park() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.park || p.closed {
+		return
+	}
+	p.logf(LogInfo, "parking %d resources", len(p.idle))
+	p.park = true
+	p.parked = append(p.parked, p.idle...)
+	p.idle = nil
+}
+
+// Put the resources held aside by Park back in the pool.
+// Waiting acquires get them straight away.
+func (p *Pool) Unpark() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if !p.park {
+		return
+	}
+	p.park = false
+	parked := p.parked
+	p.parked = nil
+	for _, r := range parked {
+		p.put(r)
+	}
+}
@@ -0,0 +1,33 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPark(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: 30 * time.Millisecond, MaxOverflow: 2})
+	a, _ := p.Acquire()
+	b := p.idle[0]
+	p.Park()
+	if s := p.Stats(); s.Idle != 0 || s.InUse != 1 {
+		t.Fatal(s)
+	}
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	p.Release(a)
+	if p.Stats().Idle != 0 {
+		t.Fatal()
+	}
+	got := make(chan Resource)
+	p.o.Timeout = time.Second
+	go func() { r, _ := p.Acquire(); got <- r }()
+	time.Sleep(20 * time.Millisecond)
+	s := time.Now()
+	p.Unpark()
+	r := <-got
+	if (r != a && r != b) || time.Since(s) > 20*time.Millisecond || p.Stats().Size != 2 || p.Stats().Idle != 1 {
+		t.Fatal(p.Stats())
+	}
+}
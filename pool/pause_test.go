@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPauseEviction(t *testing.T) {
+	r := newT()
+	r.evict = true
+	p, _ := Initialize(r, Options{PoolSize: 2, Timeout: 10 * time.Millisecond, EvictionTest: true, EvictTestSchedule: 5 * time.Millisecond})
+	p.PauseEviction()
+	time.Sleep(5 * time.Millisecond)
+	base := atomic.LoadInt64(r.evicts)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt64(r.evicts) != base {
+		t.Fatal("evicted while paused")
+	}
+	p.ResumeEviction()
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt64(r.evicts) == base {
+		t.Fatal("no evict after resume")
+	}
+	p.Close()
+}
@@ -19,14 +19,27 @@
 package pool
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type (
 	// Following is a bad example of creating a resource
 	//
+	// Only Add, Ping and Evict are required. The hooks are optional,
+	// the pool calls those a resource implements (see PreAcquirer,
+	// PostAcquirer, PreReleaser and PostReleaser).
+	// Resources are told apart by identity, so they must be
+	// comparable, typically pointers; the pool fails with
+	// ErrIncomparable for one that is not.
+	// The pool logs what happens to resources through
+	// Options.Logger, they need not log themselves.
+	//
 	// Example:
 	//        type (
 	//               Testresource struct{}
@@ -35,11 +48,9 @@ type (
 	//                return true
 	//        }
 	//        func (t *Testresource) Evict() bool {
-	//                println("Evict")
 	//                return true
 	//        }
 	//        func (t *Testresource) PreAcquire() error {
-	//                println("pre borrow")
 	//                if ok := t.Ping(); !ok {
 	//                        n, err := t.Add()
 	//                        if err != nil {
@@ -50,11 +61,9 @@ type (
 	//                return nil
 	//        }
 	//        func (t *testresource) PostAcquire() error {
-	//                println("post borrow")
 	//                return nil
 	//        }
 	//        func (t *testresource) PreRelease() error {
-	//                println("pre return")
 	//                if ok := t.Ping(); !ok {
 	//                        n, err := t.Add()
 	//                        if err != nil {
@@ -65,7 +74,6 @@ type (
 	//                return nil
 	//        }
 	//        func (t *Testresource) PostRelease() error {
-	//                println("post return")
 	//                return nil
 	//        }
 	//        func (t *Testresource) Add() (pool.Resource, error) {
@@ -76,46 +84,295 @@ type (
 		Add() (Resource, error) // Create a resource
 		Ping() bool             // Check if resource is still valid
 		Evict() bool            // Evict a resource
-		PreAcquire() error      // Process Resource Before Acquire
-		PostAcquire() error     // Process Resource After Acquire
-		PreRelease() error      // Process Resource Before Release
-		PostRelease() error     // Process Resource After Release
 	}
-	Options struct {
-		PoolSize          int64         // The number of resources in the pool
-		Timeout           time.Duration // Timeout for acquiring a resource
-		EvictionTest      bool          // Refresh the pool?
-		EvictTestSchedule time.Duration // Schedule for testing resources
+	// Optional hooks of a Resource.
+	PreAcquirer interface {
+		PreAcquire() error // Process Resource Before Acquire
+	}
+	PostAcquirer interface {
+		PostAcquire() error // Process Resource After Acquire
+	}
+	PreReleaser interface {
+		PreRelease() error // Process Resource Before Release
+	}
+	PostReleaser interface {
+		PostRelease() error // Process Resource After Release
 	}
+	// Behaviour of Release once the pool has been closed.
+	CloseMode int
+	// Order of the checks Acquire runs on a resource before handing
+	// it out. Pinging an idle resource before taking it keeps Stats
+	// accurate, a dead resource never counts as in use, but holds
+	// the pool lock for the Ping and slows every other acquire.
+	// Pinging once taken keeps the lock free at the cost of a
+	// resource counted as in use while it is checked. PreAcquire may
+	// run before Ping, e.g. when it prepares what Ping checks, or
+	// after, so it only runs on healthy resources. A resource
+	// failing Ping is replaced and Acquire tries another.
+	AcquireOrder int
+	// Behaviour of Acquire when PostAcquire fails.
+	PostAcquireMode int
+	Options         struct {
+		PoolSize                   int64                    // The number of resources in the pool
+		Timeout                    time.Duration            // Timeout for acquiring a resource
+		EvictionTest               bool                     // Refresh the pool?
+		EvictTestSchedule          time.Duration            // Schedule for testing resources
+		ReleaseAfterClose          CloseMode                // What Release does after Close
+		Lazy                       bool                     // Create resources on demand instead of on Initialize
+		MaxOverflow                int64                    // Resources created beyond PoolSize when none is idle
+		OverflowTimeout            time.Duration            // Timeout for creating an overflow resource, 0 waits for it
+		Logger                     Logger                   // Receives pool log messages, nil disables logging
+		LogLevel                   LogLevel                 // Minimum level of logged messages
+		Events                     chan<- Event             // Receives pool events when not full
+		HistorySize                int                      // Number of recent events kept for History
+		TestOnReturn               bool                     // Ping resources on Release, replacing dead ones
+		FallbackFactory            func() (Resource, error) // Creates resources while the primary factory fails
+		FallbackAfter              int                      // Consecutive primary failures before falling back
+		FallbackRetry              time.Duration            // How often to retry the primary while on fallback
+		MaxBorrowDuration          time.Duration            // Reclaim resources held longer than this, 0 never does
+		OnRecover                  func()                   // Called when a drained pool gets a resource again
+		AllowPreemption            bool                     // Let waiters flag resources held by a lower priority class
+		MaxResourceErrors          int                      // Consecutive reported errors before a resource is evicted on Release
+		OnPressure                 func(level float64)      // Called with InUse/PoolSize when it rises past a threshold
+		PressureThresholds         []float64                // Utilization thresholds, DefaultPressureThresholds if nil
+		LIFO                       bool                     // Hand out the most recently released resource first
+		QueueDiscipline            QueueDiscipline          // Order in which blocked acquirers are served
+		PrewarmKeys                []string                 // Keys given a dedicated resource on Initialize, fewer than PoolSize, see AcquireWithKey
+		TrackStacks                bool                     // Capture the stack of acquirers for Holders
+		FactoryCtx                 ContextFactory           // Creates resources instead of Resource.Add, cancelled with the acquire that needs one
+		MaxLifetime                time.Duration            // Evict resources older than this on refresh and Release, 0 keeps them
+		LifetimeJitter             time.Duration            // Refresh evicts resources up to this long before MaxLifetime, more likely the closer they get
+		ValidateOnInit             bool                     // Ping resources created by Initialize, failing if one is unhealthy
+		SpinAttempts               int                      // Times a blocked Acquire yields and retries before waiting
+		MinIdle                    int64                    // Idle resources Compact keeps
+		AsyncValidate              bool                     // Ping acquired resources in the background, replacing failed ones on Release
+		OnInvalid                  func(r Resource)         // Called when an acquired resource fails AsyncValidate
+		MinAgeBeforeEvict          time.Duration            // Refresh keeps resources younger than this whatever the policies say
+		OnPostAcquireFailure       PostAcquireMode          // What Acquire does when PostAcquire fails
+		ClassWeights               map[int]float64          // Share of released resources per priority class instead of strict priority
+		MaxEvictPerPass            int                      // Resources a refresh evicts at most, 0 for no limit
+		EvictionCooldown           time.Duration            // Refresh keeps a replacement this long after the eviction it replaced
+		PropagatePanics            bool                     // Let a panic in Ping through instead of treating the resource as dead
+		FailIfEmpty                bool                     // Acquire fails with ErrPoolEmpty instead of waiting when no resource is available
+		AdaptiveTimeout            bool                     // Acquire waits 3 times the 99th percentile of recent waits, up to Timeout
+		MinTimeout                 time.Duration            // Shortest acquire timeout with AdaptiveTimeout, Timeout/10 if 0
+		PoolMaxLifetime            time.Duration            // Close the pool this long after Initialize, 0 keeps it open
+		OnClose                    func(expired bool)       // Called once the pool closed, expired if PoolMaxLifetime closed it
+		OnHealthChange             func(Resource, bool)     // Called with false when a resource fails a Ping after passing, true when it passes again
+		MinInitSize                int64                    // Resources Initialize must create, the rest is created in the background, 0 requires all of them
+		AcquireOrder               AcquireOrder             // Checks Acquire runs on a resource and their order
+		Maintainer                 *Maintainer              // Runs the refresh passes of the pool along with others, instead of a routine of its own
+		EvictTimeout               time.Duration            // Longest wait for an AsyncEvictor to confirm its eviction, 0 waits for it
+		PriorityTimeoutMultipliers map[int]float64          // Factor of the acquire timeout per AcquirePriority class, 1 for classes not listed
+		RejectAboveQueueDepth      int                      // Blocked acquirers at most, Acquire fails with ErrQueueFull instead of waiting beyond, 0 for no limit
+		ProbeInterval              time.Duration            // Ping one idle resource this often, replacing it if it fails, 0 never does
+		PreferWarm                 bool                     // Hand out the resource released last, leaving the others idle for Compact and MaxLifetime
+		RefreshTimeout             time.Duration            // Abandon a refresh pass held up this long by a hung Evict or factory, 0 waits
+		MaxConcurrentCreations     int                      // Resources created at once at most, on every path, 0 for no limit
+		OverflowPool               *Pool                    // Pool an exhausted pool borrows from rather than wait, Release gives the resource back to it
+	}
+	// Pool of resources.
+	// All state is guarded by l. Acquire, Release, the refresh and
+	// reconfiguration such as Resize, Reset or SetEvictSchedule
+	// change the idle resources or the counts, so they hold it
+	// exclusively; read-only accessors such as Stats share it.
 	Pool struct {
-		c chan Resource // Channel for Resources
-		n int64         // number of resources in pool
-		l sync.Mutex    //Mutex
-		o Options       // pool options
+		idle    []Resource           // idle resources, the first is handed out next
+		w       []*waiter            // blocked acquirers
+		ws      waitStats            // acquire wait times
+		served  map[int]int64        // acquires per class, with Options.ClassWeights
+		s       int64                // number of resources owned by the pool
+		peak    int64                // most resources in use since the last Compact
+		mk      int                  // resources being created
+		f       ContextFactory       // creates new resources, guarded by fa.l
+		meta    map[Resource]*record // resources created by the pool
+		gen     int64                // generation, incremented on Reset
+		ids     uint64               // last resource identifier
+		hist    []Event              // recent events, ring buffer
+		hn      int                  // oldest event in hist once full
+		band    int                  // pressure thresholds reached
+		level   float64              // utilization to report to OnPressure
+		fire    bool                 // OnPressure call pending
+		keys    map[string]Resource  // resources dedicated to a key
+		spare   map[Resource]bool    // resources from AcquireOrFallback, evicted on Release
+		spill   map[Resource]bool    // resources borrowed from Options.OverflowPool, released to it
+		parked  []Resource           // idle resources held aside by Park
+		open    []Resource           // shared resources that can serve another acquirer
+		fa      factory              // factory state
+		dq      deferred             // resources waiting to be evicted
+		ae      pending              // asynchronous evictions in flight
+		hooks   atomic.Int32         // hooks running
+		hooked  sync.Map             // goroutines running a hook
+		l       sync.RWMutex         // guards the pool, see Pool
+		o       Options              // pool options
+		paused  bool                 // eviction paused
+		skip    bool                 // skip the next scheduled refresh, see SkipNextRefresh
+		due     time.Time            // next scheduled refresh
+		tick    *time.Ticker         // refresh schedule, nil without Options.EvictionTest
+		park    bool                 // resources held aside, see Park
+		heal    bool                 // recovering from a drained pool
+		closed  bool                 // pool closed
+		done    chan struct{}        // closed on Close
+		trigger chan struct{}        // requests a refresh, see TriggerRefresh
+		ctx     context.Context      // cancelled on Close, stops resource creation
+		cancel  context.CancelFunc   // cancels ctx
 	}
 )
 
+const (
+	CloseEvict CloseMode = iota // Evict resources released after Close
+	CloseError                  // Return ErrPoolClosed, the caller keeps the resource
+)
+
+const (
+	AcquireUnchecked    AcquireOrder = iota // Take the resource and run PreAcquire, no Ping
+	AcquirePingIdle                         // Ping while idle under the lock, then take it and run PreAcquire
+	AcquirePingTaken                        // Take the resource, Ping it, then run PreAcquire
+	AcquirePingPrepared                     // Take the resource, run PreAcquire, then Ping it
+)
+
+const (
+	PostAcquireRetry          PostAcquireMode = iota // Replace the resource and acquire another
+	PostAcquireReturnResource                        // Return the resource along with the error, the caller releases it
+	PostAcquireFail                                  // Replace the resource and return the error
+)
+
+var (
+	ErrPoolClosed        = errors.New("Pool closed")
+	ErrTimeout           = errors.New("Timeout")
+	ErrReclaimed         = errors.New("Resource reclaimed")
+	ErrUnknownResource   = errors.New("Resource not in pool")
+	ErrNilResource       = errors.New("Factory returned a nil resource")
+	ErrOverflowTimeout   = errors.New("Overflow timeout")
+	ErrWaitersCancelled  = errors.New("Waiters cancelled")
+	ErrUnknownPool       = errors.New("Pool not found")
+	ErrEvictDeferred     = errors.New("Eviction deferred")
+	ErrReentrantCall     = errors.New("Pool called from a resource hook")
+	ErrDuplicateResource = errors.New("Factory returned a resource already in the pool")
+	ErrPoolFull          = errors.New("Pool full")
+	ErrPoolEmpty         = errors.New("Pool empty")
+	ErrNotAcquired       = errors.New("Resource not acquired")
+	ErrThresholdExceeded = errors.New("Pool utilization above threshold")
+	ErrPingFailed        = errors.New("Resource failed Ping")
+	ErrEvictTimeout      = errors.New("Eviction not confirmed in time")
+	ErrQueueFull         = errors.New("Too many acquirers waiting")
+	ErrIncomparable      = errors.New("Resource not comparable, use a pointer")
+)
+
 // Internal function for testing/refreshing resources.
-func (p *Pool) refreshPool() {
+// Each resource is taken out of the idle ones while it is tested
+// and replaced, so the lock is not held across Evict or the
+// factory and acquires go on meanwhile. The resource kept, or its
+// replacement, goes back where it was so the order resources are
+// handed out in is not changed. Gives up before the next resource
+// once stop is closed.
+func (p *Pool) refreshPool(stop <-chan struct{}) {
 	p.l.Lock()
-	defer p.l.Unlock()
-	for i := int64(0); i < p.n; i++ {
+	v := p.triage()
+	p.l.Unlock()
+	n := 0
+	for _, r := range v {
+		if p.o.MaxEvictPerPass > 0 && n >= p.o.MaxEvictPerPass {
+			// Spread the replacement of many resources over passes
+			return
+		}
 		select {
-		case r := <-p.c:
-			if r.Evict() {
-				t, err := r.Add()
-				if err != nil {
-					break
-				}
-				r = t
+		case <-stop:
+			return
+		default:
+		}
+		p.l.Lock()
+		at := -1
+		for i, v := range p.idle {
+			if v == r {
+				at = i
+				break
 			}
-			p.c <- r
-		case <-time.After(p.o.Timeout):
+		}
+		if at < 0 || p.young(r) || p.cooling(r) {
+			p.l.Unlock()
 			continue
 		}
+		var next Resource
+		if at+1 < len(p.idle) {
+			next = p.idle[at+1]
+		}
+		p.idle = append(p.idle[:at], p.idle[at+1:]...)
+		p.meta[identity(r)].tested = time.Now()
+		evict := p.retire(r) || p.early(r)
+		p.l.Unlock()
+		if evict {
+			p.evict(r)
+		} else {
+			evict = p.test(r)
+		}
+		if !evict {
+			p.restore(r, at, next)
+			continue
+		}
+		n++
+		p.logf(LogDebug, "evicted resource %v", r)
+		p.l.Lock()
+		key := p.keyOf(r)
+		p.forget(r)
+		peer := p.peer()
+		p.l.Unlock()
+		t, err := p.create(p.ctx, peer)
+		if err != nil {
+			continue
+		}
+		p.l.Lock()
+		p.succeed(t, key)
+		p.meta[identity(t)].tested = time.Now()
+		p.l.Unlock()
+		p.restore(t, at, next)
 	}
 }
 
+// Internal function putting a resource taken out by the refresh
+// back among the idle resources, before next if it is still idle
+// or at position at otherwise. Like fill, the resource goes to a
+// waiter if there is one and is evicted if the pool was closed.
+func (p *Pool) restore(r Resource, at int, next Resource) {
+	if !p.fill(r) {
+		return
+	}
+	p.l.Lock()
+	defer p.l.Unlock()
+	n := len(p.idle) - 1
+	if n < 0 || p.idle[n] != r {
+		// Handed to a waiter
+		return
+	}
+	for i, v := range p.idle[:n] {
+		if v == next {
+			at = i
+			break
+		}
+	}
+	if at < n {
+		copy(p.idle[at+1:], p.idle[at:n])
+		p.idle[at] = r
+	}
+}
+
+// Idle resources the next refresh evicts regardless of their
+// Evict test, which is not run: those past Options.MaxLifetime,
+// created before the last Reset or with too many errors in a
+// row. Nothing is evicted. Used to check eviction settings
+// before relying on them.
+func (p *Pool) EvictionPreview() []Resource {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	var v []Resource
+	for _, r := range p.idle {
+		if p.retire(r) && !p.young(r) && !p.cooling(r) {
+			v = append(v, r)
+		}
+	}
+	return v
+}
+
 // Initialize a pool
 //
 // Usage:
@@ -124,64 +381,1272 @@ func (p *Pool) refreshPool() {
 //	p, _ := pool.Initialize(r, pool.Options{PoolSize: 10,
 //		Timeout:           time.Second,
 //		EvictionTest:      true,
-//		EvictTestSchedule: time.Second * 1}
+//		EvictTestSchedule: time.Second * 1,
+//		Logger:            log.Default(), // evictions, timeouts, failed creations
+//		LogLevel:          pool.LogDebug}
 //      )
 //
 func Initialize(r Resource, o Options) (*Pool, error) {
+	var f func() (Resource, error)
+	if r != nil {
+		f = r.Add
+	}
+	return initialize(context.Background(), f, o, nil)
+}
+
+// Initialize a pool as Initialize does, calling progress with the
+// number of resources created so far each time one is ready, up
+// to the number the pool starts with. Gives up with the error of
+// ctx once it is done, evicting the resources created until then.
+//
+// Usage:
+//
+//	p, err := pool.InitializeProgress(ctx, r, o, func(done, total int) {
+//		log.Printf("%d/%d connections ready", done, total)
+//	})
+func InitializeProgress(ctx context.Context, r Resource, o Options, progress func(done, total int)) (*Pool, error) {
+	var f func() (Resource, error)
+	if r != nil {
+		f = r.Add
+	}
+	return initialize(ctx, f, o, progress)
+}
+
+// Internal function initializing a pool creating resources with f
+// within ctx, reporting each one to progress if it is set.
+func initialize(ctx context.Context, f func() (Resource, error), o Options, progress func(done, total int)) (*Pool, error) {
 	p := new(Pool)
-	p.c = make(chan Resource, o.PoolSize)
-	for i := int64(0); i < o.PoolSize; i++ {
-		r, err := r.Add()
+	p.done = make(chan struct{})
+	p.trigger = make(chan struct{}, 1)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.f = o.FactoryCtx
+	if p.f == nil {
+		p.f = plain(f)
+	}
+	p.o = o
+	if o.MaxConcurrentCreations > 0 {
+		p.fa.sem = make(chan struct{}, o.MaxConcurrentCreations)
+	}
+	// Give up on the pool, evicting what was created so far
+	fail := func(err error) (*Pool, error) {
+		for _, v := range p.idle {
+			p.evict(v)
+		}
+		p.cancel()
+		return nil, err
+	}
+	if len(o.PrewarmKeys) > 0 && int64(len(o.PrewarmKeys)) >= o.PoolSize {
+		// Plain acquires never get a dedicated resource
+		return fail(errors.New("PrewarmKeys must leave resources for plain acquires"))
+	}
+	n := o.PoolSize
+	if o.Lazy {
+		n = int64(len(o.PrewarmKeys))
+	}
+	for i := int64(0); i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		r, err := p.derive(ctx, nil)
+		if err != nil && o.MinInitSize > 0 && i >= o.MinInitSize {
+			// Start degraded, the rest is created in the background
+			p.logf(LogWarn, "starting with %d of %d resources: %v", i, n, err)
+			break
+		}
 		if err != nil {
-			return nil, err
+			return fail(err)
+		}
+		if o.ValidateOnInit && !p.ping(r) {
+			// Fail fast rather than serve a misconfigured pool
+			p.evict(r)
+			return fail(errors.New("Resource failed Ping on Initialize"))
+		}
+		if err := p.track(r); err != nil {
+			if err == ErrIncomparable {
+				p.evict(r)
+			}
+			// A duplicate is already among the idle resources
+			return fail(err)
+		}
+		p.idle = append(p.idle, r)
+		if progress != nil {
+			progress(int(i+1), int(n))
 		}
-		p.c <- r
 	}
-	p.n = o.PoolSize
-	p.o = o
+	p.s = int64(len(p.idle))
+	for i, k := range o.PrewarmKeys {
+		if i == len(p.idle) {
+			break
+		}
+		p.pin(p.idle[i], k)
+	}
+	if p.s < n && !o.Lazy {
+		p.heal = true
+		go p.recover(false)
+	}
+	if o.MaxBorrowDuration > 0 {
+		go p.sweep()
+	}
+	if o.PoolMaxLifetime > 0 {
+		go p.expire()
+	}
+	if o.ProbeInterval > 0 {
+		go p.prober()
+	}
+	if o.Maintainer != nil {
+		o.Maintainer.add(p)
+		return p, nil
+	}
 	// If pool needs to be tested, schedule the refresh
+	var tick <-chan time.Time
+	stop := func() {}
 	if o.EvictionTest {
-		tick := time.NewTicker(o.EvictTestSchedule)
-		go func() {
-			for _ = range tick.C {
-				p.refreshPool()
-			}
-		}()
+		p.tick = time.NewTicker(o.EvictTestSchedule)
+		tick, stop = p.tick.C, p.tick.Stop
+		p.due = time.Now().Add(o.EvictTestSchedule)
 	}
+	go func() {
+		defer stop()
+		for {
+			select {
+			case <-tick:
+				p.l.Lock()
+				p.due = time.Now().Add(p.o.EvictTestSchedule)
+				p.l.Unlock()
+				if p.skipped() {
+					continue
+				}
+			case <-p.trigger:
+			case <-p.done:
+				return
+			}
+			p.refresh()
+		}
+	}()
 	return p, nil
 }
 
+// Time of the next scheduled refresh, zero without
+// Options.EvictionTest. Refreshes asked for with TriggerRefresh
+// come on top.
+func (p *Pool) NextRefresh() time.Time {
+	if !p.o.EvictionTest {
+		return time.Time{}
+	}
+	if m := p.o.Maintainer; m != nil {
+		m.l.Lock()
+		defer m.l.Unlock()
+		return m.next
+	}
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.due
+}
+
+// Skip the next scheduled refresh, e.g. right after a manual one.
+// Skipping again before it came skips it only once.
+func (p *Pool) SkipNextRefresh() {
+	p.l.Lock()
+	p.skip = true
+	p.l.Unlock()
+}
+
+// Change Options.EvictTestSchedule of a running pool. The next
+// refresh comes d from now. Ignored if d is not positive; with
+// Options.Maintainer the schedule is that of the maintainer.
+func (p *Pool) SetEvictSchedule(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.o.EvictTestSchedule = d
+	if p.tick != nil {
+		p.tick.Reset(d)
+		p.due = time.Now().Add(d)
+	}
+}
+
+// Internal function checking if a scheduled refresh is to be
+// skipped, which it is only once per SkipNextRefresh.
+func (p *Pool) skipped() bool {
+	p.l.Lock()
+	defer p.l.Unlock()
+	skip := p.skip
+	p.skip = false
+	return skip
+}
+
+// Internal function running a refresh pass unless eviction is
+// paused or the pool closed. A pass taking longer than
+// Options.RefreshTimeout is abandoned.
+func (p *Pool) refresh() {
+	p.l.Lock()
+	skip := p.paused || p.closed
+	p.l.Unlock()
+	if skip {
+		return
+	}
+	if p.o.RefreshTimeout <= 0 {
+		p.refreshPool(nil)
+		return
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.refreshPool(stop)
+	}()
+	t := time.NewTimer(p.o.RefreshTimeout)
+	defer t.Stop()
+	select {
+	case <-done:
+	case <-t.C:
+		// A hung Evict or factory holds up only the resource it
+		// tests, the rest of the pass is abandoned
+		close(stop)
+		p.logf(LogWarn, "refresh stuck for %v, pass abandoned", p.o.RefreshTimeout)
+	}
+}
+
+// Refresh the pool now rather than on the next tick of the
+// schedule, e.g. right after a backend failover. Works without
+// Options.EvictionTest too. Returns at once; refreshes requested
+// while one is pending are merged.
+func (p *Pool) TriggerRefresh() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+	}
+	if p.o.Maintainer != nil {
+		p.o.Maintainer.poke()
+	}
+}
+
+// Pause the eviction test.
+// The schedule keeps ticking but resources are not tested
+// until ResumeEviction is called. Useful during maintenance
+// windows where every test would fail.
+func (p *Pool) PauseEviction() {
+	p.l.Lock()
+	p.paused = true
+	p.l.Unlock()
+}
+
+// Resume the eviction test after PauseEviction.
+func (p *Pool) ResumeEviction() {
+	p.l.Lock()
+	p.paused = false
+	p.l.Unlock()
+}
+
 // Acquire a resource from the pool.
 // Will time out if option is set.
 func (p *Pool) Acquire() (r Resource, err error) {
-	select {
-	case r = <-p.c:
-		if err = r.PreAcquire(); err != nil {
+	r, _, err = p.AcquireWithPosition()
+	return r, err
+}
+
+// Acquire a resource from the pool, giving up once ctx is done.
+// Options.Timeout still applies. A resource created for this
+// acquire, by a lazy pool or as overflow, is created with a
+// context cancelled along with ctx, see Options.FactoryCtx.
+// Returns the error of ctx if it ends the acquire.
+func (p *Pool) AcquireContext(ctx context.Context) (Resource, error) {
+	r, _, err := p.checkout(request{timeout: p.EffectiveTimeout(), ctx: ctx})
+	return r, err
+}
+
+// Acquire a resource from the pool only while less than the
+// threshold share of PoolSize is in use, failing with
+// ErrThresholdExceeded at once otherwise. Lets background work
+// back off while foreground traffic keeps the pool busy.
+//
+// Usage:
+//
+//	r, err := p.AcquireIfBelow(0.8)
+//	if err == pool.ErrThresholdExceeded {
+//		// try again later
+//	}
+func (p *Pool) AcquireIfBelow(threshold float64) (Resource, error) {
+	r, _, err := p.checkout(request{timeout: p.EffectiveTimeout(), below: threshold})
+	return r, err
+}
+
+// Acquire a resource from the pool before the deadline t.
+// Callers sharing a fixed budget across several operations pass
+// the same deadline to each. A deadline in the past only gets a
+// resource that is available at once.
+func (p *Pool) AcquireDeadline(t time.Time) (Resource, error) {
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	r, _, err := p.checkout(request{timeout: d})
+	return r, err
+}
+
+// Acquire a resource from the pool, waiting a little longer than
+// timeout if a resource is being created when it runs out. The
+// grace period is the average creation latency, or timeout again
+// until a resource has been created. Also reports whether the
+// grace period was used. Reduces timeouts while a pool warms up.
+func (p *Pool) AcquireBestEffort(timeout time.Duration) (Resource, bool, error) {
+	var graced bool
+	r, _, err := p.checkout(request{timeout: timeout, graced: &graced})
+	return r, graced, err
+}
+
+// Acquire an idle resource or, if there is none, create one
+// even beyond PoolSize instead of waiting. Such a resource is
+// evicted on Release rather than growing the pool. Guarantees
+// progress to critical callers at the cost of creating more
+// resources than the pool is meant to hold.
+func (p *Pool) AcquireOrCreate() (Resource, error) {
+	r, _, err := p.checkout(request{force: true})
+	return r, err
+}
+
+// Acquire a resource from the pool, getting one from fallback
+// if the acquire times out. The fallback resource is not pooled
+// and is evicted on Release. Also reports whether fallback was
+// used. Gives callers a way to degrade gracefully.
+func (p *Pool) AcquireOrFallback(fallback func() (Resource, error)) (Resource, bool, error) {
+	r, err := p.Acquire()
+	if err != ErrTimeout {
+		return r, false, err
+	}
+	if r, err = fallback(); err != nil {
+		return nil, true, err
+	}
+	if !trackable(r) {
+		r.Evict()
+		return nil, true, ErrIncomparable
+	}
+	p.l.Lock()
+	if p.spare == nil {
+		p.spare = make(map[Resource]bool)
+	}
+	p.spare[r] = true
+	p.l.Unlock()
+	return r, true, nil
+}
+
+// Acquire a resource from the pool, also returning the number
+// of acquirers that were already waiting when this one had to
+// wait, or 0 if a resource was available at once.
+func (p *Pool) AcquireWithPosition() (r Resource, pos int, err error) {
+	return p.checkout(request{timeout: p.EffectiveTimeout()})
+}
+
+// Acquire a resource from the pool with a priority class.
+// When a resource is released it goes to the waiter with the
+// highest class, earliest arrival first within a class. Acquire
+// uses class 0. With Options.AllowPreemption a waiting caller
+// also flags a resource held by a lower class, see Preempted.
+// Options.PriorityTimeoutMultipliers lets high classes wait longer
+// and low ones fail fast under contention.
+func (p *Pool) AcquirePriority(class int) (Resource, error) {
+	timeout := p.EffectiveTimeout()
+	if f, ok := p.o.PriorityTimeoutMultipliers[class]; ok && f > 0 {
+		timeout = time.Duration(float64(timeout) * f)
+	}
+	r, _, err := p.checkout(request{timeout: timeout, class: class})
+	return r, err
+}
+
+// Acquire a given resource again.
+// Returns r if it is idle, otherwise waits for it to be released
+// or times out. Resources that are not in the pool, for instance
+// because they were evicted, give ErrUnknownResource, and so do
+// those that fail the checks of Acquire, which are replaced. Used
+// to pin a session to the resource it used before.
+func (p *Pool) AcquireSpecific(r Resource) (Resource, error) {
+	r, _, err := p.checkout(request{timeout: p.EffectiveTimeout(), want: r})
+	return r, err
+}
+
+// Acquire a resource making up to attempts tries within total.
+// The budget is split evenly between attempts; an attempt that
+// fails early, e.g. because a resource could not be created,
+// waits out its share before the next one. Gives up at once if
+// the pool is closed and never takes longer than total.
+func (p *Pool) AcquireBudget(total time.Duration, attempts int) (Resource, error) {
+	if attempts < 1 {
+		attempts = 1
+	}
+	deadline := time.Now().Add(total)
+	err := ErrTimeout
+	for i := attempts; i > 0; i-- {
+		left := time.Until(deadline)
+		if left <= 0 {
+			break
+		}
+		share := left / time.Duration(i)
+		end := time.Now().Add(share)
+		var r Resource
+		if r, _, err = p.checkout(request{timeout: share}); err == nil || r != nil {
+			// r comes with the error of PostAcquireReturnResource
+			return r, err
+		}
+		if err == ErrPoolClosed {
 			return nil, err
 		}
+		p.logf(LogDebug, "acquire attempt %d of %d failed: %v", attempts-i+1, attempts, err)
+		if i > 1 {
+			time.Sleep(time.Until(end))
+		}
+	}
+	return nil, err
+}
+
+// Acquire a resource, starting a second attempt if the first has
+// not succeeded within delay. Whichever attempt gets a resource
+// first wins; the other is cancelled and a resource it still gets
+// is released. Cuts tail latency when one acquire is stuck, for
+// instance behind a slow creation.
+func (p *Pool) AcquireHedged(delay time.Duration) (Resource, error) {
+	type result struct {
+		r   Resource
+		err error
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan result, 2)
+	try := func() {
+		r, err := p.AcquireContext(ctx)
+		c <- result{r, err}
+	}
+	go try()
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case v := <-c:
+		return v.r, v.err
+	case <-t.C:
+	}
+	go try()
+	v := <-c
+	left := 1
+	if v.r == nil {
+		v = <-c
+		left = 0
+	}
+	cancel()
+	if left > 0 {
+		go func() {
+			if o := <-c; o.r != nil {
+				p.Release(o.r)
+			}
+		}()
+	}
+	return v.r, v.err
+}
+
+// Internal function acquiring a resource and running the acquire
+// hooks on it.
+func (p *Pool) checkout(q request) (r Resource, pos int, err error) {
+	if p.reentrant() {
+		return nil, 0, ErrReentrantCall
+	}
+	r, pos, err = p.acquire(q)
+	p.signal()
+	if err != nil {
+		return nil, pos, err
+	}
+	if p.borrowed(r) {
+		// Checked by the pool it comes from
+		return r, pos, nil
+	}
+	return p.ready(q, r, pos)
+}
+
+// Internal function running the checks and hooks of Acquire on r,
+// just taken out of the pool. A resource failing them is replaced
+// and another one acquired, see Options.OnPostAcquireFailure.
+func (p *Pool) ready(q request, r Resource, pos int) (Resource, int, error) {
+	if p.o.TrackStacks {
+		p.trace(r)
+	}
+	if p.o.AcquireOrder == AcquirePingTaken {
+		if !p.ping(r) {
+			return p.retry(q, r, pos, ErrPingFailed)
+		}
+		q.pinged()
+	}
+	if err := p.hook(preAcquire, r); err != nil {
+		// The resource is in an unknown state, do not leak it
+		p.replace(r)
+		return nil, pos, err
+	}
+	if p.o.AcquireOrder == AcquirePingPrepared {
+		if !p.ping(r) {
+			return p.retry(q, r, pos, ErrPingFailed)
+		}
+		q.pinged()
+	}
+	if err := p.hook(postAcquire, r); err != nil {
+		switch p.o.OnPostAcquireFailure {
+		case PostAcquireReturnResource:
+			return r, pos, err
+		case PostAcquireFail:
+			p.replace(r)
+			return nil, pos, err
+		}
+		return p.retry(q, r, pos, err)
+	}
+	if p.o.AsyncValidate {
+		go p.check(r)
+	}
+	return r, pos, nil
+}
+
+// Internal function replacing a resource that failed a check on
+// Acquire and acquiring another.
+func (p *Pool) retry(q request, r Resource, pos int, err error) (Resource, int, error) {
+	p.replace(r)
+	p.l.RLock()
+	size := p.o.PoolSize
+	p.l.RUnlock()
+	// Give up once as many resources as the pool holds failed
+	if q.tries++; int64(q.tries) >= size {
+		return nil, pos, err
+	}
+	p.logf(LogWarn, "acquiring another resource: %v", err)
+	return p.checkout(q)
+}
+
+// Internal function taking a resource out of the pool.
+// Hands out an idle resource, grows a lazy pool or waits
+// for a resource to be released.
+func (p *Pool) acquire(q request) (Resource, int, error) {
+	var pos int
+	start := time.Now()
+	p.l.Lock()
+	if p.closed {
+		p.l.Unlock()
+		return nil, pos, ErrPoolClosed
+	}
+	if q.res != nil {
+		// Left over from an attempt that failed a check
+		*q.res = Result{}
+	}
+	if q.below > 0 && p.o.PoolSize > 0 && float64(p.s-int64(len(p.idle)))/float64(p.o.PoolSize) >= q.below {
+		p.l.Unlock()
+		return nil, pos, ErrThresholdExceeded
+	}
+	if q.want != nil {
+		if _, ok := p.meta[identity(q.want)]; !ok {
+			p.l.Unlock()
+			return nil, pos, ErrUnknownResource
+		}
+		if p.pick(q.want) {
+			if !p.sound(q.want) {
+				// Gone once replaced, as if it had been evicted
+				p.l.Unlock()
+				p.logf(LogDebug, "skipping broken resource %v", q.want)
+				p.replace(q.want)
+				return nil, pos, ErrUnknownResource
+			}
+			if p.o.AcquireOrder == AcquirePingIdle {
+				q.pinged()
+			}
+			p.lend(q.want, q.class, 0)
+			p.l.Unlock()
+			return q.want, pos, nil
+		}
+	} else if r := p.keyed(q.key); r != nil {
+		if p.o.AcquireOrder == AcquirePingIdle {
+			q.pinged()
+		}
+		p.lend(r, q.class, 0)
+		p.l.Unlock()
+		return r, pos, nil
+	} else if r := p.local(q.zone); r != nil {
+		p.lend(r, q.class, 0)
+		p.l.Unlock()
+		return r, pos, nil
+	} else if r := p.reuse(); r != nil {
+		if p.o.AcquireOrder == AcquirePingIdle {
+			q.pinged()
+		}
+		p.lend(r, q.class, 0)
+		p.l.Unlock()
+		return r, pos, nil
+	} else if r := p.usable(); r != nil {
+		if p.o.AcquireOrder == AcquirePingIdle {
+			q.pinged()
+		}
+		p.lend(r, q.class, 0)
+		p.l.Unlock()
+		return r, pos, nil
+	}
+	lazy := p.o.Lazy && p.s < p.o.PoolSize
+	if q.want == nil && !p.park && (lazy || p.s < p.o.PoolSize+p.o.MaxOverflow) {
+		// Nothing idle, a lazy pool may still grow or overflow.
+		// Counting the resource before creating it caps creations
+		// at the room left, however many acquirers arrive at once.
+		p.s++
+		p.l.Unlock()
+		ctx, cancel := p.ctx, context.CancelFunc(func() {})
+		if q.ctx != nil {
+			ctx, cancel = p.join(q.ctx)
+		}
+		defer cancel()
+		var r Resource
+		var err error
+		if lazy {
+			r, err = p.create(ctx, nil)
+		} else {
+			r, err = p.overflow(ctx)
+		}
+		if err != nil {
+			return nil, pos, err
+		}
+		if q.res != nil {
+			q.res.Fresh = true
+		}
 		p.l.Lock()
-		p.n--
+		p.lend(r, q.class, time.Since(start))
 		p.l.Unlock()
-		if err = r.PreAcquire(); err != nil {
-			return nil, err
+		return r, pos, nil
+	}
+	if q.force {
+		// Beyond the size cap, evicted on Release
+		p.s++
+		p.l.Unlock()
+		r, err := p.create(p.ctx, nil)
+		if err != nil {
+			return nil, pos, err
+		}
+		if q.res != nil {
+			q.res.Fresh = true
+		}
+		p.l.Lock()
+		p.meta[identity(r)].unpooled = true
+		p.lend(r, q.class, time.Since(start))
+		p.l.Unlock()
+		return r, pos, nil
+	}
+	if o := p.o.OverflowPool; o != nil && q.want == nil && !q.spilled {
+		// Exhausted, borrow from the secondary pool rather than wait
+		p.l.Unlock()
+		r, _, err := o.checkout(request{class: q.class, ctx: q.ctx, nowait: true, spilled: true})
+		if err != nil {
+			if r != nil {
+				// Failed PostAcquire with PostAcquireReturnResource
+				o.Release(r)
+			}
+			q.spilled = true
+			return p.acquire(q)
+		}
+		p.l.Lock()
+		if p.spill == nil {
+			p.spill = make(map[Resource]bool)
+		}
+		p.spill[r] = true
+		p.l.Unlock()
+		return r, pos, nil
+	}
+	if (p.o.FailIfEmpty || q.nowait) && q.want == nil {
+		p.l.Unlock()
+		return nil, pos, ErrPoolEmpty
+	}
+	// Spin briefly, a resource held for a short time may soon be back
+	for i := 0; i < p.o.SpinAttempts && q.want == nil && !p.closed; i++ {
+		p.l.Unlock()
+		runtime.Gosched()
+		p.l.Lock()
+		if r := p.usable(); r != nil {
+			if p.o.AcquireOrder == AcquirePingIdle {
+				q.pinged()
+			}
+			p.lend(r, q.class, time.Since(start))
+			p.l.Unlock()
+			return r, pos, nil
+		}
+	}
+	if p.closed {
+		p.l.Unlock()
+		return nil, pos, ErrPoolClosed
+	}
+	if n := p.o.RejectAboveQueueDepth; n > 0 && len(p.w)+1 > n {
+		p.l.Unlock()
+		return nil, pos, ErrQueueFull
+	}
+	w := newWaiter(q)
+	pos = len(p.w)
+	p.w = append(p.w, w)
+	if p.o.AllowPreemption && q.want == nil {
+		p.preempt(q.class)
+	}
+	p.l.Unlock()
+	var done <-chan struct{}
+	if q.ctx != nil {
+		done = q.ctx.Done()
+	}
+	t := time.NewTimer(q.timeout)
+	defer t.Stop()
+	var err error
+	for err == nil {
+		select {
+		case r, ok := <-w.c:
+			if !ok {
+				return nil, pos, w.err
+			}
+			return r, pos, nil
+		case <-t.C:
+			err = ErrTimeout
+			if d := p.grace(q); d > 0 {
+				t.Reset(d)
+				err = nil
+			}
+		case <-done:
+			err = q.ctx.Err()
+		}
+	}
+	p.l.Lock()
+	if !p.removeWaiter(w) {
+		// A resource was handed over as the timer fired
+		p.l.Unlock()
+		if r, ok := <-w.c; ok {
+			return r, pos, nil
+		}
+		return nil, pos, w.err
+	}
+	if err != ErrTimeout {
+		p.l.Unlock()
+		return nil, pos, err
+	}
+	p.ws.t++
+	p.ws.observe(p.o.Timeout)
+	p.emit(EventTimeout, nil, q.timeout)
+	p.l.Unlock()
+	p.logf(LogWarn, "acquire timed out after %v", q.timeout)
+	return nil, pos, err
+}
+
+// Internal function returning how much longer an acquire that
+// timed out waits, 0 for not at all.
+func (p *Pool) grace(q request) time.Duration {
+	if q.graced == nil || *q.graced {
+		return 0
+	}
+	p.l.RLock()
+	mk := p.mk
+	p.l.RUnlock()
+	if mk == 0 {
+		return 0
+	}
+	*q.graced = true
+	if d := p.CreationLatency(); d > 0 {
+		return d
+	}
+	return q.timeout
+}
+
+// Internal function taking the next idle resource that passes
+// sound. Broken ones are replaced in the background rather than
+// handed out.
+// Must be called with the lock held.
+func (p *Pool) usable() Resource {
+	for {
+		r := p.take()
+		if r == nil {
+			return nil
+		}
+		if p.sound(r) {
+			return r
+		}
+		p.logf(LogDebug, "skipping broken resource %v", r)
+		go p.replace(r)
+	}
+}
+
+// Internal function checking that r can be handed out: it was not
+// reported broken, has a valid local state and does not fail Ping
+// with AcquirePingIdle.
+// Must be called with the lock held.
+func (p *Pool) sound(r Resource) bool {
+	ok := !p.broken(r) && valid(r)
+	if ok && p.o.AcquireOrder == AcquirePingIdle {
+		ok = p.probe(r)
+		if p.o.OnHealthChange != nil && p.mark(r, ok) {
+			go p.o.OnHealthChange(r, ok)
+		}
+	}
+	return ok
+}
+
+// Internal function taking the next idle resource.
+// Idle resources are kept in release order, so the pool is a
+// queue that becomes a stack with Options.LIFO.
+// Returns nil if there is none.
+// Must be called with the lock held.
+func (p *Pool) take() Resource {
+	if len(p.idle) == 0 {
+		return nil
+	}
+	if p.o.PreferWarm {
+		return p.warmest()
+	}
+	if len(p.keys) > 0 {
+		// Skip resources dedicated to a key
+		for j := range p.idle {
+			i := j
+			if p.o.LIFO {
+				i = len(p.idle) - 1 - j
+			}
+			if r := p.idle[i]; !p.dedicated(r) {
+				p.idle = append(p.idle[:i], p.idle[i+1:]...)
+				return r
+			}
+		}
+		return nil
+	}
+	if p.o.LIFO {
+		n := len(p.idle) - 1
+		r := p.idle[n]
+		p.idle[n] = nil
+		p.idle = p.idle[:n]
+		return r
+	}
+	r := p.idle[0]
+	p.idle = p.idle[1:]
+	return r
+}
+
+// Internal function taking a given idle resource.
+// Returns false if it is not idle.
+// Must be called with the lock held.
+func (p *Pool) pick(r Resource) bool {
+	for i, v := range p.idle {
+		if v == r {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Internal function taking the idle resource released last,
+// whatever its place in the idle list, see Options.PreferWarm.
+// Returns nil if all are dedicated to a key.
+// Must be called with the lock held.
+func (p *Pool) warmest() Resource {
+	i := -1
+	var t time.Time
+	for j, r := range p.idle {
+		if p.dedicated(r) {
+			continue
+		}
+		var freed time.Time
+		if m, ok := p.meta[identity(r)]; ok {
+			freed = m.freed
+		}
+		if i < 0 || !freed.Before(t) {
+			i, t = j, freed
+		}
+	}
+	if i < 0 {
+		return nil
+	}
+	r := p.idle[i]
+	p.idle = append(p.idle[:i], p.idle[i+1:]...)
+	return r
+}
+
+// Internal function moving an idle resource to the end of the
+// idle list that take hands out next.
+// Must be called with the lock held.
+func (p *Pool) promote(r Resource) {
+	n := len(p.idle) - 1
+	if n < 0 || p.idle[n] != r {
+		// Handed to a waiter
+		return
+	}
+	if !p.o.LIFO {
+		copy(p.idle[1:], p.idle[:n])
+		p.idle[0] = r
+	}
+}
+
+// Internal function returning a resource to the pool.
+// The next waiter gets it, if there is one.
+// Must be called with the lock held.
+func (p *Pool) put(r Resource) {
+	if p.park {
+		p.unlend(r)
+		p.parked = append(p.parked, r)
+		return
+	}
+	if i := p.next(r); i >= 0 {
+		w := p.w[i]
+		p.w = append(p.w[:i], p.w[i+1:]...)
+		p.lend(r, w.class, time.Since(w.t))
+		w.c <- r
+		return
+	}
+	p.unlend(r)
+	p.idle = append(p.idle, r)
+	p.pressure()
+}
+
+// Internal function creating a resource, cloned from peer if
+// possible.
+// The caller reserves room for it by incrementing the number
+// of owned resources, which is given back if creation fails.
+func (p *Pool) create(ctx context.Context, peer Resource) (Resource, error) {
+	p.l.Lock()
+	p.mk++
+	p.l.Unlock()
+	r, err := p.renew(ctx, peer)
+	p.l.Lock()
+	p.mk--
+	if err == nil {
+		err = p.track(r)
+	}
+	if err != nil {
+		p.shrink()
+		p.l.Unlock()
+		if err == ErrIncomparable {
+			p.evict(r)
+		}
+		p.logf(LogWarn, "creating resource: %v", err)
+		return nil, err
+	}
+	p.l.Unlock()
+	return r, nil
+}
+
+// Internal function creating an overflow resource, giving up
+// after Options.OverflowTimeout. A resource created after that
+// is kept in the pool for the next acquire.
+// The caller reserves room for it as with create.
+func (p *Pool) overflow(ctx context.Context) (Resource, error) {
+	if p.o.OverflowTimeout <= 0 {
+		return p.create(ctx, nil)
+	}
+	type result struct {
+		r   Resource
+		err error
+	}
+	c := make(chan result, 1)
+	go func() {
+		r, err := p.create(ctx, nil)
+		c <- result{r, err}
+	}()
+	t := time.NewTimer(p.o.OverflowTimeout)
+	defer t.Stop()
+	select {
+	case v := <-c:
+		return v.r, v.err
+	case <-t.C:
+	}
+	go func() {
+		if v := <-c; v.err == nil {
+			p.fill(v.r)
 		}
-		return r, err
-	case <-time.After(p.o.Timeout):
-		return nil, errors.New("Timeout")
+	}()
+	p.l.Lock()
+	p.ws.t++
+	p.emit(EventTimeout, nil, p.o.OverflowTimeout)
+	p.l.Unlock()
+	p.logf(LogWarn, "overflow creation timed out after %v", p.o.OverflowTimeout)
+	return nil, ErrOverflowTimeout
+}
+
+// Internal function evicting a resource and putting a fresh
+// one in its place. The pool shrinks if creation fails.
+func (p *Pool) replace(r Resource) {
+	p.l.Lock()
+	key := p.keyOf(r)
+	p.forget(r)
+	peer := p.peer()
+	p.l.Unlock()
+	p.evict(r)
+	p.logf(LogDebug, "evicted resource %v", r)
+	if n, err := p.create(p.ctx, peer); err == nil {
+		p.l.Lock()
+		p.succeed(n, key)
+		p.l.Unlock()
+		p.fill(n)
 	}
 }
 
+// Internal function putting a newly created resource in the pool.
+// Returns false, evicting the resource, if the pool was closed
+// in the meantime.
+func (p *Pool) fill(r Resource) bool {
+	p.l.Lock()
+	if p.closed {
+		p.s--
+		p.forget(r)
+		p.l.Unlock()
+		p.evict(r)
+		return false
+	}
+	p.put(r)
+	p.l.Unlock()
+	return true
+}
+
+// Warm the pool by creating resources until it owns target
+// resources or PoolSize is reached, whichever is lower.
+// Use it to fill a lazy pool ahead of a known spike; run it
+// in a goroutine to keep it off the hot path. On error or
+// cancellation the resources created so far stay in the pool
+// and the returned error reports the progress made; a creation in
+// flight is not waited for once ctx is done.
+func (p *Pool) Warm(ctx context.Context, target int64) error {
+	ctx, cancel := p.join(ctx)
+	defer cancel()
+	var created int64
+	for {
+		p.l.Lock()
+		if p.closed {
+			p.l.Unlock()
+			return ErrPoolClosed
+		}
+		if p.s >= target || p.s >= p.o.PoolSize {
+			p.l.Unlock()
+			return nil
+		}
+		p.s++
+		p.l.Unlock()
+		if err := ctx.Err(); err != nil {
+			p.l.Lock()
+			p.s--
+			p.l.Unlock()
+			return fmt.Errorf("Warm: created %d resources: %w", created, err)
+		}
+		r, err := p.create(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("Warm: created %d resources: %w", created, err)
+		}
+		if !p.fill(r) {
+			return ErrPoolClosed
+		}
+		created++
+	}
+}
+
+// Internal function checking if r was borrowed from
+// Options.OverflowPool.
+func (p *Pool) borrowed(r Resource) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return p.spill[r]
+}
+
+// Internal function returning Options.OverflowPool if r was
+// borrowed from it, which r is then given back to, nil otherwise.
+func (p *Pool) lender(r Resource) *Pool {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if !p.spill[r] {
+		return nil
+	}
+	delete(p.spill, r)
+	return p.o.OverflowPool
+}
+
 // Release a resource back to the pool
+// Returns ErrNotAcquired if the resource is already idle and
+// ErrUnknownResource if it does not belong to the pool.
 func (p *Pool) Release(r Resource) (err error) {
-	if err := r.PreRelease(); err != nil {
+	return p.release(context.Background(), r, false)
+}
+
+// Release a resource to the front of the pool.
+// It is the next one handed out, instead of the last as with
+// Release. Use it for resources the caller knows to be healthy.
+func (p *Pool) ReleaseFront(r Resource) error {
+	return p.release(context.Background(), r, true)
+}
+
+// Release a resource back to the pool, giving up on slow release
+// hooks once ctx is done. A resource whose PreRelease did not
+// finish in time is evicted and replaced rather than put back in
+// an unknown state; PostRelease runs once the resource is back.
+// Returns the error of ctx if it cut a hook short.
+func (p *Pool) ReleaseContext(ctx context.Context, r Resource) error {
+	return p.release(ctx, r, false)
+}
+
+// Release a resource the caller knows the state of. An unhealthy
+// resource, e.g. a connection that just failed a query, is
+// evicted and replaced instead of going back to the pool.
+func (p *Pool) ReleaseWithVerdict(r Resource, healthy bool) error {
+	if o := p.lender(r); o != nil {
+		return o.ReleaseWithVerdict(r, healthy)
+	}
+	if !healthy {
+		p.l.Lock()
+		if m, ok := p.meta[identity(r)]; ok && !m.borrowed.IsZero() {
+			m.invalid = true
+			p.rate(r, -1)
+		}
+		p.l.Unlock()
+	}
+	return p.release(context.Background(), r, false)
+}
+
+// Release a batch of resources back to the pool.
+// Every resource is released as with Release, even after one of
+// them failed; the errors are joined. Simplifies the clean up of
+// batch workers.
+func (p *Pool) ReleaseAll(rs []Resource) error {
+	var errs []error
+	for _, r := range rs {
+		errs = append(errs, p.release(context.Background(), r, false))
+	}
+	return errors.Join(errs...)
+}
+
+// Internal function releasing a resource, to the front of the
+// pool if asked to.
+func (p *Pool) release(ctx context.Context, r Resource, front bool) (err error) {
+	if p.reentrant() {
+		return ErrReentrantCall
+	}
+	if o := p.lender(r); o != nil {
+		return o.release(ctx, r, front)
+	}
+	if p.reclaimed(r) {
+		return ErrReclaimed
+	}
+	p.l.Lock()
+	m, ok := p.meta[identity(r)]
+	if ok && m.borrowed.IsZero() {
+		// Released twice
+		p.l.Unlock()
+		return ErrNotAcquired
+	}
+	spare := p.spare[r]
+	if !ok && !spare {
+		// Not from this pool, see Owns
+		p.l.Unlock()
+		return ErrUnknownResource
+	}
+	delete(p.spare, r)
+	p.l.Unlock()
+	if spare {
+		p.evict(r)
+		return nil
+	}
+	if err := p.hookContext(ctx, preRelease, r); err != nil {
+		if err == ctx.Err() {
+			// The hook did not finish, the state of r is unknown
+			p.logf(LogWarn, "PreRelease of resource %v cut short, evicting it: %v", r, err)
+			p.replace(r)
+		}
 		return err
 	}
-	p.c <- r
 	p.l.Lock()
-	p.n++
+	shared := p.unshare(r)
+	p.l.Unlock()
+	if shared {
+		// Other acquirers still hold it
+		return p.hookContext(ctx, postRelease, r)
+	}
+	dead := p.o.TestOnReturn && !p.ping(r)
+	p.l.Lock()
+	if p.closed {
+		if p.o.ReleaseAfterClose == CloseError {
+			p.l.Unlock()
+			return ErrPoolClosed
+		}
+		p.s--
+		p.forget(r)
+		p.l.Unlock()
+		p.evict(r)
+		return nil
+	}
+	p.emit(EventRelease, r, p.held(r))
+	if m, ok := p.meta[identity(r)]; ok && m.unpooled || p.s > p.o.PoolSize+p.o.MaxOverflow {
+		// Created by AcquireOrCreate or the pool was resized down
+		p.s--
+		p.forget(r)
+		p.l.Unlock()
+		p.evict(r)
+		return nil
+	}
+	// Replace dead, flaky, old resources and those created before the last Reset
+	if dead || p.retire(r) {
+		p.l.Unlock()
+		p.replace(r)
+		return nil
+	}
+	p.rate(r, 1)
+	p.put(r)
+	if front {
+		p.promote(r)
+	}
 	p.l.Unlock()
-	if err := r.PostRelease(); err != nil {
+	if err := p.hookContext(ctx, postRelease, r); err != nil {
 		return err
 	}
 	return err
 }
+
+// Close the pool.
+// Idle resources are evicted, the refresh schedule is stopped and
+// resource creations in progress are abandoned. Returns once every
+// AsyncEvictor confirmed its eviction, or ErrEvictTimeout if one
+// did not within Options.EvictTimeout.
+// Resources still outstanding are handled on Release according
+// to Options.ReleaseAfterClose.
+func (p *Pool) Close() error {
+	return p.shut(false)
+}
+
+// Internal function closing the pool, expired if it outlived
+// Options.PoolMaxLifetime.
+func (p *Pool) shut(expired bool) error {
+	// Cancel creations first, a refresh may be holding the lock
+	p.cancel()
+	p.l.Lock()
+	if p.closed {
+		p.l.Unlock()
+		return ErrPoolClosed
+	}
+	p.closed = true
+	close(p.done)
+	p.dismiss(ErrPoolClosed)
+	idle := append(append([]Resource(nil), p.idle...), p.parked...)
+	for _, r := range idle {
+		p.s--
+		p.forget(r)
+	}
+	p.idle = nil
+	p.parked = nil
+	p.l.Unlock()
+	// Evict without the lock, Evict may be slow or use the pool
+	for _, r := range idle {
+		p.evict(r)
+	}
+	if p.o.Maintainer != nil {
+		p.o.Maintainer.remove(p)
+	}
+	err := p.settle()
+	if p.o.OnClose != nil {
+		p.o.OnClose(expired)
+	}
+	return err
+}
+
+// Internal function closing the pool once it reached
+// Options.PoolMaxLifetime. Resources still acquired are handled
+// on Release according to Options.ReleaseAfterClose, as after
+// Close.
+func (p *Pool) expire() {
+	t := time.NewTimer(p.o.PoolMaxLifetime)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		p.logf(LogInfo, "pool reached its lifetime of %v, closing it", p.o.PoolMaxLifetime)
+		p.shut(true)
+	case <-p.done:
+	}
+}
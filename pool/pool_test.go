@@ -0,0 +1,41 @@
+package pool
+
+import "sync/atomic"
+
+// Resource counting the calls the pool makes.
+type tres struct {
+	id      int64
+	evicts  *int64 // Evict calls, shared by the resources of a pool
+	adds    *int64 // Add calls, shared by the resources of a pool
+	evict   bool   // what Evict returns
+	ping    atomic.Bool
+	evicted atomic.Bool
+}
+
+var tid int64
+
+func newT() *tres {
+	t := &tres{evicts: new(int64), adds: new(int64)}
+	t.ping.Store(true)
+	return t
+}
+
+func (t *tres) Add() (Resource, error) {
+	atomic.AddInt64(t.adds, 1)
+	n := &tres{id: atomic.AddInt64(&tid, 1), evicts: t.evicts, adds: t.adds, evict: t.evict}
+	n.ping.Store(true)
+	return n, nil
+}
+
+func (t *tres) Ping() bool { return t.ping.Load() }
+
+func (t *tres) Evict() bool {
+	atomic.AddInt64(t.evicts, 1)
+	t.evicted.Store(true)
+	return t.evict
+}
+
+func (t *tres) PreAcquire() error  { return nil }
+func (t *tres) PostAcquire() error { return nil }
+func (t *tres) PreRelease() error  { return nil }
+func (t *tres) PostRelease() error { return nil }
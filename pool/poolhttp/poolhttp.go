@@ -0,0 +1,66 @@
+// HTTP endpoints to inspect and adjust a pool at runtime.
+//
+// The handler answers with JSON:
+//
+//	GET  /stats          pool.Stats
+//	POST /refresh        refreshes the pool now
+//	POST /resize?size=n  changes the pool size to n
+//
+// Example:
+//
+//	http.Handle("/debug/pool/", http.StripPrefix("/debug/pool", poolhttp.Handler(p)))
+package poolhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/avarghes1/go_pool/pool"
+)
+
+// Handler serving the admin endpoints of p.
+func Handler(p *pool.Pool) http.Handler {
+	m := http.NewServeMux()
+	m.HandleFunc("/stats", only(http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusOK, p.Stats())
+	}))
+	m.HandleFunc("/refresh", only(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		p.TriggerRefresh()
+		reply(w, http.StatusAccepted, map[string]string{"status": "refresh triggered"})
+	}))
+	m.HandleFunc("/resize", only(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		n, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64)
+		if err != nil {
+			reply(w, http.StatusBadRequest, map[string]string{"error": "size must be an integer"})
+			return
+		}
+		if err := p.Resize(n); err != nil {
+			reply(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		reply(w, http.StatusOK, p.Stats())
+	}))
+	return m
+}
+
+// Restrict h to requests with the given method. Checked here
+// rather than with "GET /stats" patterns, which modules on Go
+// versions before 1.22 take for literal paths.
+func only(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			reply(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		h(w, r)
+	}
+}
+
+// Write v as the JSON body of a response with the given status.
+func reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,52 @@
+package poolhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/avarghes1/go_pool/pool"
+)
+
+type res struct{}
+
+func (res) Add() (pool.Resource, error) { return &res2{}, nil }
+func (res) Ping() bool                  { return true }
+func (res) Evict() bool                 { return true }
+
+type res2 struct {
+	res
+	n int
+}
+
+func TestHandler(t *testing.T) {
+	p, _ := pool.Initialize(res{}, pool.Options{PoolSize: 2, Timeout: time.Second})
+	s := httptest.NewServer(Handler(p))
+	defer s.Close()
+	r, err := http.Get(s.URL + "/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st pool.Stats
+	if err := json.NewDecoder(r.Body).Decode(&st); err != nil || st.Size != 2 {
+		t.Fatal(err, st)
+	}
+	r, _ = http.Post(s.URL+"/resize?size=5", "", nil)
+	if r.StatusCode != 200 || p.Stats().Size != 5 {
+		t.Fatal(r.StatusCode, p.Stats())
+	}
+	r, _ = http.Post(s.URL+"/resize?size=x", "", nil)
+	if r.StatusCode != 400 {
+		t.Fatal(r.StatusCode)
+	}
+	r, _ = http.Post(s.URL+"/refresh", "", nil)
+	if r.StatusCode != 202 {
+		t.Fatal(r.StatusCode)
+	}
+	r, _ = http.Get(s.URL + "/resize?size=1")
+	if r.StatusCode != 405 || r.Header.Get("Allow") != "POST" || p.Stats().Size != 5 {
+		t.Fatal(r.StatusCode, p.Stats())
+	}
+}
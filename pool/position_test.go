@@ -0,0 +1,25 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireWithPosition(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second})
+	a, pos, _ := p.AcquireWithPosition()
+	if pos != 0 {
+		t.Fatal(pos)
+	}
+	ch := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() { r, pos, _ := p.AcquireWithPosition(); ch <- pos; p.Release(r) }()
+		time.Sleep(20 * time.Millisecond)
+	}
+	p.Release(a)
+	for i := 0; i < 3; i++ {
+		if v := <-ch; v != i {
+			t.Fatal(v, i)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type postRes struct {
+	*tres
+	fails *int64
+}
+
+func (s *postRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &postRes{r.(*tres), s.fails}, nil
+}
+func (s *postRes) PostAcquire() error {
+	if atomic.AddInt64(s.fails, -1) >= 0 {
+		return errors.New("post")
+	}
+	return nil
+}
+
+func TestPostAcquireFailure(t *testing.T) {
+	for _, m := range []PostAcquireMode{PostAcquireRetry, PostAcquireReturnResource, PostAcquireFail} {
+		n := int64(1)
+		p, _ := Initialize(&postRes{newT(), &n}, Options{PoolSize: 2, Timeout: time.Second, OnPostAcquireFailure: m})
+		r, err := p.Acquire()
+		switch m {
+		case PostAcquireRetry:
+			if err != nil || r == nil {
+				t.Fatal(m, err)
+			}
+		case PostAcquireReturnResource:
+			if err == nil || r == nil {
+				t.Fatal(m, err)
+			}
+		case PostAcquireFail:
+			if err == nil || r != nil {
+				t.Fatal(m, err)
+			}
+			if p.Stats().Idle != 2 {
+				t.Fatal(p.Stats())
+			}
+		}
+	}
+	n := int64(100)
+	p, _ := Initialize(&postRes{newT(), &n}, Options{PoolSize: 2, Timeout: time.Second})
+	if _, err := p.Acquire(); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestPostAcquireReturnResource(t *testing.T) {
+	n := int64(0)
+	p, _ := Initialize(&postRes{newT(), &n}, Options{PoolSize: 2, Timeout: 20 * time.Millisecond, OnPostAcquireFailure: PostAcquireReturnResource})
+	fail := func() { atomic.StoreInt64(&n, 1) }
+	fail()
+	if _, err := p.Reserve(2); err == nil || p.Stats().Idle != 2 {
+		t.Fatal(err, p.Stats())
+	}
+	fail()
+	if r, err := p.AcquireBudget(time.Second, 3); err == nil || r == nil || p.Release(r) != nil {
+		t.Fatal(err)
+	}
+	fail()
+	if r, err := p.AcquireHedged(time.Millisecond); err == nil || r == nil || p.Release(r) != nil {
+		t.Fatal(err)
+	}
+	m := NewMultiPool(map[string]*Pool{"a": p}, func(string) string { return "a" })
+	fail()
+	if r, err := m.Acquire("k"); err == nil || r == nil || m.Release(r) != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if s := p.Stats(); s.Idle != 2 {
+		t.Fatal(s)
+	}
+}
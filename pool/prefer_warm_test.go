@@ -0,0 +1,23 @@
+package pool
+
+import "testing"
+
+func TestPreferWarm(t *testing.T) {
+	for _, warm := range []bool{false, true} {
+		p, _ := Initialize(newT(), Options{PoolSize: 5, PreferWarm: warm})
+		n := map[Resource]int{}
+		for i := 0; i < 100; i++ {
+			r, _ := p.Acquire()
+			n[r]++
+			if i%10 == 0 {
+				r2, _ := p.Acquire()
+				n[r2]++
+				p.Release(r2)
+			}
+			p.Release(r)
+		}
+		if warm && len(n) > 2 || !warm && len(n) != 5 {
+			t.Fatal(warm, n)
+		}
+	}
+}
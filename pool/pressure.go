@@ -0,0 +1,48 @@
+package pool
+
+// Thresholds used when Options.PressureThresholds is not set.
+var DefaultPressureThresholds = []float64{0.8, 0.95}
+
+// Internal function checking utilization against the pressure
+// thresholds after a resource was acquired or released.
+// Rising past a threshold queues a call to Options.OnPressure;
+// falling back below one only rearms it, so a pool hovering
+// around a threshold does not fire repeatedly.
+// Must be called with the lock held.
+func (p *Pool) pressure() {
+	if p.o.OnPressure == nil || p.o.PoolSize <= 0 {
+		return
+	}
+	t := p.o.PressureThresholds
+	if t == nil {
+		t = DefaultPressureThresholds
+	}
+	level := float64(p.s-int64(len(p.idle))) / float64(p.o.PoolSize)
+	band := 0
+	for _, v := range t {
+		if level >= v {
+			band++
+		}
+	}
+	if band > p.band {
+		p.level = level
+		p.fire = true
+	}
+	p.band = band
+}
+
+// Internal function calling Options.OnPressure if a threshold
+// was crossed. Called without the lock so the callback may use
+// the pool.
+func (p *Pool) signal() {
+	if p.o.OnPressure == nil {
+		return
+	}
+	p.l.Lock()
+	fire, level := p.fire, p.level
+	p.fire = false
+	p.l.Unlock()
+	if fire {
+		p.o.OnPressure(level)
+	}
+}
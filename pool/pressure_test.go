@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPressure(t *testing.T) {
+	var got []float64
+	p, _ := Initialize(newT(), Options{PoolSize: 20, Timeout: 10 * time.Millisecond, OnPressure: func(l float64) { got = append(got, l) }})
+	var rs []Resource
+	for i := 0; i < 20; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	if len(got) != 2 || got[0] != 0.8 || got[1] != 0.95 {
+		t.Fatal(got)
+	}
+	p.Release(rs[0])
+	p.Release(rs[1])
+	r, _ := p.Acquire()
+	_ = r
+	if len(got) != 3 {
+		t.Fatal(got)
+	}
+}
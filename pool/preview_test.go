@@ -0,0 +1,33 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvictionPreview(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: time.Second, MaxLifetime: time.Hour})
+	p.l.Lock()
+	a := p.idle[0]
+	p.meta[a].created = time.Now().Add(-2 * time.Hour)
+	b := p.idle[1]
+	p.l.Unlock()
+	v := p.EvictionPreview()
+	if len(v) != 1 || v[0] != a {
+		t.Fatal(v)
+	}
+	p.refreshPool(nil)
+	p.l.Lock()
+	for _, r := range p.idle {
+		if r == a {
+			t.Fatal("a kept")
+		}
+	}
+	if _, ok := p.meta[b]; !ok {
+		t.Fatal("b gone")
+	}
+	p.l.Unlock()
+	if len(p.EvictionPreview()) != 0 {
+		t.Fatal("after")
+	}
+}
@@ -0,0 +1,27 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquirePriority(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, AllowPreemption: true})
+	a, _ := p.Acquire()
+	ch := make(chan int, 3)
+	for i := 0; i < 2; i++ {
+		go func() { r, _ := p.AcquirePriority(0); ch <- 0; time.Sleep(10 * time.Millisecond); p.Release(r) }()
+	}
+	time.Sleep(20 * time.Millisecond)
+	go func() { r, _ := p.AcquirePriority(5); ch <- 5; time.Sleep(10 * time.Millisecond); p.Release(r) }()
+	time.Sleep(20 * time.Millisecond)
+	if !p.Preempted(a) {
+		t.Fatal("not preempted")
+	}
+	p.Release(a)
+	if v := <-ch; v != 5 {
+		t.Fatal(v)
+	}
+	<-ch
+	<-ch
+}
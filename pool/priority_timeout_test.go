@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityTimeoutMultipliers(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 40 * time.Millisecond,
+		PriorityTimeoutMultipliers: map[int]float64{1: 3, -1: 0.25}})
+	r, _ := p.Acquire()
+	defer p.Release(r)
+	var wg sync.WaitGroup
+	took := map[int]time.Duration{}
+	var l sync.Mutex
+	for _, c := range []int{-1, 0, 1} {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			s := time.Now()
+			if _, err := p.AcquirePriority(c); err != ErrTimeout {
+				t.Error(c, err)
+			}
+			l.Lock()
+			took[c] = time.Since(s)
+			l.Unlock()
+		}(c)
+	}
+	wg.Wait()
+	if took[-1] > 25*time.Millisecond || took[0] < 40*time.Millisecond || took[0] > 80*time.Millisecond || took[1] < 120*time.Millisecond {
+		t.Fatal(took)
+	}
+}
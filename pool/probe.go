@@ -0,0 +1,49 @@
+package pool
+
+import "time"
+
+// Internal function pinging one idle resource every
+// Options.ProbeInterval, the one probed longest ago, so failures
+// are found between refresh passes without pinging every resource
+// at once. A resource failing the Ping is replaced.
+func (p *Pool) prober() {
+	tick := time.NewTicker(p.o.ProbeInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			p.probeIdle()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Internal function probing the idle resource probed longest ago.
+// It is taken out of the idle resources during the Ping, so it is
+// not handed out meanwhile.
+func (p *Pool) probeIdle() {
+	p.l.Lock()
+	i := -1
+	var oldest time.Time
+	for j, r := range p.idle {
+		m, ok := p.meta[identity(r)]
+		if ok && (i < 0 || m.probed.Before(oldest)) {
+			i, oldest = j, m.probed
+		}
+	}
+	if i < 0 {
+		p.l.Unlock()
+		return
+	}
+	r := p.idle[i]
+	p.idle = append(p.idle[:i], p.idle[i+1:]...)
+	p.meta[identity(r)].probed = time.Now()
+	p.l.Unlock()
+	if p.ping(r) {
+		p.fill(r)
+		return
+	}
+	p.logf(LogWarn, "resource %v failed probe", r)
+	p.replace(r)
+}
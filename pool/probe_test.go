@@ -0,0 +1,32 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeInterval(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: time.Second, ProbeInterval: 10 * time.Millisecond,
+		EvictionTest: true, EvictTestSchedule: time.Hour})
+	defer p.Close()
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	p.ReleaseAll(rs)
+	bad := rs[1].(*tres)
+	bad.ping.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if !bad.evicted.Load() || p.Owns(bad) {
+		t.Fatal("not replaced")
+	}
+	if s := p.Stats(); s.Size != 3 || s.Idle != 3 {
+		t.Fatal(s)
+	}
+	for _, r := range []Resource{rs[0], rs[2]} {
+		if r.(*tres).evicted.Load() || !p.Owns(r) {
+			t.Fatal("healthy replaced")
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInitializeProgress(t *testing.T) {
+	var got []int
+	p, err := InitializeProgress(context.Background(), newT(), Options{PoolSize: 5, Timeout: time.Second}, func(done, total int) {
+		if total != 5 {
+			t.Fatal(total)
+		}
+		got = append(got, done)
+	})
+	if err != nil || p.Stats().Idle != 5 {
+		t.Fatal(err)
+	}
+	for i, d := range got {
+		if d != i+1 {
+			t.Fatal(got)
+		}
+	}
+	if len(got) != 5 {
+		t.Fatal(got)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := newT()
+	_, err = InitializeProgress(ctx, r, Options{PoolSize: 5}, func(done, total int) {
+		if done == 2 {
+			cancel()
+		}
+	})
+	if err != context.Canceled || *r.evicts != 2 {
+		t.Fatal(err, *r.evicts)
+	}
+}
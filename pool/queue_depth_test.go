@@ -0,0 +1,43 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRejectAboveQueueDepth(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, RejectAboveQueueDepth: 3})
+	r, _ := p.Acquire()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r, err := p.Acquire(); err == nil {
+				p.Release(r)
+			} else {
+				t.Error(err)
+			}
+		}()
+	}
+	for p.Stats().Waiters < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	var rejected sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		rejected.Add(1)
+		go func() {
+			defer rejected.Done()
+			if _, err := p.Acquire(); err != ErrQueueFull {
+				t.Error(err)
+			}
+		}()
+	}
+	rejected.Wait()
+	if p.Stats().Waiters != 3 {
+		t.Fatal(p.Stats())
+	}
+	p.Release(r)
+	wg.Wait()
+}
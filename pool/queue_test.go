@@ -0,0 +1,47 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func order(t *testing.T, d QueueDiscipline) []int {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, QueueDiscipline: d})
+	a, _ := p.Acquire()
+	ch := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		go func(i int) { r, _ := p.Acquire(); ch <- i; p.Release(r) }(i)
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Release(a)
+	var got []int
+	for i := 0; i < 5; i++ {
+		got = append(got, <-ch)
+	}
+	return got
+}
+
+func TestQueueDiscipline(t *testing.T) {
+	if g := order(t, QueueFIFO); g[0] != 0 || g[4] != 4 {
+		t.Fatal(g)
+	}
+	if g := order(t, QueueLIFO); g[0] != 4 || g[1] != 3 {
+		t.Fatal(g)
+	}
+	// Every waiter served once, and not always in arrival order
+	fifo := true
+	for n := 0; n < 20 && fifo; n++ {
+		g := order(t, QueueRandom)
+		seen := make(map[int]bool)
+		for i, v := range g {
+			seen[v] = true
+			fifo = fifo && v == i
+		}
+		if len(seen) != 5 {
+			t.Fatal(g)
+		}
+	}
+	if fifo {
+		t.Fatal("random queue always FIFO")
+	}
+}
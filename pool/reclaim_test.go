@@ -0,0 +1,23 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBorrowDeadline(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 200 * time.Millisecond, MaxBorrowDuration: 20 * time.Millisecond})
+	a, _ := p.Acquire()
+	b, err := p.Acquire()
+	if err != nil || a == b || !a.(*tres).evicted.Load() {
+		t.Fatal(err)
+	}
+	if p.Release(a) != ErrReclaimed {
+		t.Fatal()
+	}
+	p.Release(b)
+	if len(p.meta) != 1 {
+		t.Fatal(len(p.meta))
+	}
+	p.Close()
+}
@@ -0,0 +1,403 @@
+package pool
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const healthMax = 10 // highest health score of a resource
+
+type (
+	// What the pool knows about a resource it created.
+	record struct {
+		r         Resource  // the resource as handed out, the key is its identity
+		id        uint64    // identifier used in events
+		gen       int64     // generation the resource was created in
+		created   time.Time // when the resource was created
+		borrowed  time.Time // when the resource was acquired, zero while idle
+		reclaimed bool      // evicted by the pool while acquired
+		class     int       // priority class of the holder
+		preempted bool      // holder asked to release early
+		errs      int       // consecutive reported errors
+		total     int       // reported errors
+		uses      int64     // times acquired
+		shares    int       // holders of a SharedResource
+		score     int       // health, down on failures and up on successful use
+		key       string    // key the resource is dedicated to
+		stack     string    // stack of the holder, with Options.TrackStacks
+		invalid   bool      // failed validation while acquired
+		unpooled  bool      // created beyond PoolSize, evicted on Release
+		replaced  time.Time // when the resource this one replaces was evicted
+		sick      bool      // failed its last Ping
+		retired   bool      // reported broken with Retire
+		freed     time.Time // when the resource last became idle
+		probed    time.Time // when the resource was last pinged by Options.ProbeInterval
+		spent     spans     // time spent idle and acquired
+		cross     bool      // acquired outside the zone asked for, see AcquireZone
+		tested    time.Time // when the refresh last tested the resource or created it as a replacement
+		reserved  bool      // set aside by a Reservation, see Reserve
+	}
+	// Time a resource spent in each state, see Utilization.
+	spans struct {
+		idle time.Duration // idle in the pool, until freed
+		busy time.Duration // acquired
+	}
+)
+
+// Internal function registering a newly created resource.
+// Resources are tracked by identity, so they must be comparable
+// (typically pointers), ErrIncomparable otherwise. Returns
+// ErrDuplicateResource for one the pool already has, which is
+// left alone.
+// Must be called with the lock held.
+func (p *Pool) track(r Resource) error {
+	if !trackable(r) {
+		return ErrIncomparable
+	}
+	if p.meta == nil {
+		p.meta = make(map[Resource]*record)
+	}
+	if _, ok := p.meta[identity(r)]; ok {
+		return ErrDuplicateResource
+	}
+	p.ids++
+	now := time.Now()
+	p.meta[identity(r)] = &record{r: r, id: p.ids, gen: p.gen, created: now, freed: now}
+	p.emit(EventCreate, r, 0)
+	return nil
+}
+
+// Internal function taking over a resource created by another
+// pool, keeping its history but not its state in that pool.
+// Must be called with the lock held.
+func (p *Pool) adopt(r Resource, m *record) {
+	if p.meta == nil {
+		p.meta = make(map[Resource]*record)
+	}
+	if m == nil {
+		m = &record{created: time.Now()}
+	}
+	p.ids++
+	p.meta[identity(r)] = &record{r: r, id: p.ids, gen: p.gen, created: m.created, total: m.total, uses: m.uses,
+		freed: time.Now(), spent: m.spent}
+}
+
+// Internal function forgetting an evicted resource.
+// Must be called with the lock held.
+func (p *Pool) forget(r Resource) {
+	m, ok := p.meta[identity(r)]
+	if ok && !m.reclaimed {
+		p.emit(EventEvict, r, 0)
+	}
+	if ok && m.key != "" && p.keys[m.key] == r {
+		delete(p.keys, m.key)
+	}
+	if ok && m.shares > 0 {
+		p.reopen(r, false)
+	}
+	delete(p.meta, identity(r))
+}
+
+// Report if r belongs to the pool, idle or acquired.
+// Lets callers handling several pools check where a resource
+// goes before releasing it. Evicted and reclaimed resources no
+// longer belong to the pool.
+func (p *Pool) Owns(r Resource) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	m, ok := p.meta[identity(r)]
+	return ok && !m.reclaimed
+}
+
+// Internal function marking a resource as acquired by a caller
+// of the given class that waited for it.
+// Must be called with the lock held.
+func (p *Pool) lend(r Resource, class int, wait time.Duration) {
+	p.ws.record(wait)
+	if len(p.o.ClassWeights) > 0 {
+		if p.served == nil {
+			p.served = make(map[int]int64)
+		}
+		p.served[class]++
+	}
+	if m, ok := p.meta[identity(r)]; ok {
+		if m.borrowed.IsZero() {
+			m.spent.idle += time.Since(m.freed)
+		}
+		m.borrowed = time.Now()
+		m.class = class
+		m.uses++
+		p.cohold(r, m)
+	}
+	if n := p.s - int64(len(p.idle)); n > p.peak {
+		p.peak = n
+	}
+	p.emit(EventAcquire, r, wait)
+	p.pressure()
+}
+
+// Internal function returning for how long a resource has been
+// acquired.
+// Must be called with the lock held.
+func (p *Pool) held(r Resource) time.Duration {
+	if m, ok := p.meta[identity(r)]; ok && !m.borrowed.IsZero() {
+		return time.Since(m.borrowed)
+	}
+	return 0
+}
+
+// Time a resource spent idle in the pool and acquired so far,
+// both 0 for a resource the pool does not own. Mostly idle
+// resources point to an oversized pool, mostly busy ones to an
+// undersized one.
+func (p *Pool) Utilization(r Resource) (idle, busy time.Duration) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	m, ok := p.meta[identity(r)]
+	if !ok {
+		return 0, 0
+	}
+	idle, busy = m.spent.idle, m.spent.busy
+	if m.borrowed.IsZero() {
+		idle += time.Since(m.freed)
+	} else {
+		busy += time.Since(m.borrowed)
+	}
+	return idle, busy
+}
+
+// Internal function marking a resource as idle.
+// Must be called with the lock held.
+func (p *Pool) unlend(r Resource) {
+	if m, ok := p.meta[identity(r)]; ok {
+		if !m.borrowed.IsZero() {
+			m.spent.busy += time.Since(m.borrowed)
+			m.freed = time.Now()
+		}
+		m.borrowed = time.Time{}
+		m.preempted = false
+		m.cross = false
+		m.stack = ""
+	}
+}
+
+// Internal function checking if a resource was created before
+// the last Reset.
+// Must be called with the lock held.
+func (p *Pool) stale(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	return ok && m.gen < p.gen
+}
+
+// Reset the pool.
+// Idle resources are evicted and replaced straight away, resources
+// currently acquired are evicted and replaced when released.
+func (p *Pool) Reset() error {
+	p.l.Lock()
+	if p.closed {
+		p.l.Unlock()
+		return ErrPoolClosed
+	}
+	p.gen++
+	idle := p.idle
+	p.idle = nil
+	p.l.Unlock()
+	for _, r := range idle {
+		p.replace(r)
+	}
+	return nil
+}
+
+// Report an error a resource caused while in use.
+// Once Options.MaxResourceErrors errors are reported in a row the
+// resource is evicted and replaced on Release. Reporting a nil
+// error ends the streak.
+func (p *Pool) ReportError(r Resource, err error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	m, ok := p.meta[identity(r)]
+	if !ok {
+		return
+	}
+	if err == nil {
+		m.errs = 0
+		return
+	}
+	m.errs++
+	m.total++
+	p.rate(r, -1)
+	p.logf(LogDebug, "resource %v reported error %d in a row: %v", r, m.errs, err)
+}
+
+// Report a resource as broken, e.g. after the backend behind it
+// went away. Acquire no longer hands it out: an idle resource is
+// replaced by the next Acquire that comes across it, an acquired
+// one on Release.
+func (p *Pool) Retire(r Resource) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if m, ok := p.meta[identity(r)]; ok {
+		m.retired = true
+		p.logf(LogDebug, "resource %v retired", r)
+	}
+}
+
+// Internal function checking if a resource is older than
+// Options.MaxLifetime.
+// Must be called with the lock held.
+func (p *Pool) old(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	return ok && p.o.MaxLifetime > 0 && time.Since(m.created) >= p.o.MaxLifetime
+}
+
+// Internal function picking resources to evict ahead of
+// Options.MaxLifetime, so resources created together are not all
+// replaced by the same refresh. Within Options.LifetimeJitter of
+// the deadline the chance grows linearly with the age.
+// Must be called with the lock held.
+func (p *Pool) early(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	if !ok || p.o.MaxLifetime <= 0 || p.o.LifetimeJitter <= 0 {
+		return false
+	}
+	into := time.Since(m.created) - (p.o.MaxLifetime - p.o.LifetimeJitter)
+	return into > 0 && rand.Float64() < float64(into)/float64(p.o.LifetimeJitter)
+}
+
+// Internal function checking if a resource is younger than
+// Options.MinAgeBeforeEvict.
+// Must be called with the lock held.
+func (p *Pool) young(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	return ok && time.Since(m.created) < p.o.MinAgeBeforeEvict
+}
+
+// Internal function checking if a resource replaced an evicted
+// one less than Options.EvictionCooldown ago.
+// Must be called with the lock held.
+func (p *Pool) cooling(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	return ok && !m.replaced.IsZero() && time.Since(m.replaced) < p.o.EvictionCooldown
+}
+
+// Internal function handing a replacement what it takes over from
+// the evicted resource: its key and the time of the eviction.
+// Must be called with the lock held.
+func (p *Pool) succeed(r Resource, key string) {
+	p.pin(r, key)
+	if m, ok := p.meta[identity(r)]; ok {
+		m.replaced = time.Now()
+	}
+}
+
+// Internal function checking if the pool's own policies evict a
+// resource, whatever it says about itself.
+// Must be called with the lock held.
+func (p *Pool) retire(r Resource) bool {
+	return p.stale(r) || p.old(r) || p.broken(r)
+}
+
+// Internal function checking if a resource was reported broken,
+// by too many errors, a failed validation or Retire.
+// Must be called with the lock held.
+func (p *Pool) broken(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	return p.flaky(r) || ok && (m.invalid || m.retired)
+}
+
+// Internal function checking if a resource reported too many
+// errors in a row.
+// Must be called with the lock held.
+func (p *Pool) flaky(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	return ok && p.o.MaxResourceErrors > 0 && m.errs >= p.o.MaxResourceErrors
+}
+
+// Internal function pinging an acquired resource for
+// Options.AsyncValidate. A resource failing it is replaced on
+// Release and reported to Options.OnInvalid, so the holder can
+// give it back and retry with another one.
+func (p *Pool) check(r Resource) {
+	if p.ping(r) {
+		return
+	}
+	p.l.Lock()
+	m, ok := p.meta[identity(r)]
+	if ok {
+		m.invalid = true
+		p.rate(r, -1)
+	}
+	p.l.Unlock()
+	if !ok {
+		return
+	}
+	p.logf(LogWarn, "resource %v failed validation", r)
+	if p.o.OnInvalid != nil {
+		p.o.OnInvalid(r)
+	}
+}
+
+// Internal function changing the health score of a resource by d.
+// Must be called with the lock held.
+func (p *Pool) rate(r Resource, d int) {
+	if m, ok := p.meta[identity(r)]; ok {
+		m.score += d
+		if m.score > healthMax {
+			m.score = healthMax
+		}
+	}
+}
+
+// Health scores of the resources in the pool.
+// A score goes down on every failed validation or reported error
+// and up, to at most 10, on every release after a successful use.
+// The refresh tests the lowest scoring idle resources first, so
+// the flakiest ones are recycled first.
+func (p *Pool) Health() map[Resource]int {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	h := make(map[Resource]int, len(p.meta))
+	for _, m := range p.meta {
+		if !m.reclaimed {
+			h[m.r] = m.score
+		}
+	}
+	return h
+}
+
+// Internal function returning the idle resources in the order
+// the refresh tests them: by health score, lowest first, then
+// those tested longest ago, so a pass cut short by
+// Options.MaxEvictPerPass is taken up by the next one. The idle
+// list itself is left as is.
+// Must be called with the lock held.
+func (p *Pool) triage() []Resource {
+	v := append([]Resource(nil), p.idle...)
+	m := make([]*record, len(v))
+	for i, r := range v {
+		if m[i] = p.meta[identity(r)]; m[i] == nil {
+			m[i] = &record{}
+		}
+	}
+	sort.Stable(byHealth{v, m})
+	return v
+}
+
+// Idle resources with their records, sorted by triage.
+type byHealth struct {
+	v []Resource
+	m []*record
+}
+
+func (b byHealth) Len() int { return len(b.v) }
+func (b byHealth) Less(i, j int) bool {
+	if b.m[i].score != b.m[j].score {
+		return b.m[i].score < b.m[j].score
+	}
+	return b.m[i].tested.Before(b.m[j].tested)
+}
+func (b byHealth) Swap(i, j int) {
+	b.v[i], b.v[j] = b.v[j], b.v[i]
+	b.m[i], b.m[j] = b.m[j], b.m[i]
+}
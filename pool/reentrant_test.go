@@ -0,0 +1,43 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type hookRes struct {
+	*tres
+	p   **Pool
+	err *error
+}
+
+func (s *hookRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &hookRes{r.(*tres), s.p, s.err}, nil
+}
+
+func (s *hookRes) PreRelease() error {
+	_, err := (*s.p).Acquire()
+	*s.err = err
+	return nil
+}
+
+func TestReentrantCall(t *testing.T) {
+	var p *Pool
+	var herr error
+	p, _ = Initialize(&hookRes{newT(), &p, &herr}, Options{PoolSize: 1, Timeout: 10 * time.Second})
+	r, _ := p.Acquire()
+	done := make(chan error)
+	go func() { done <- p.Release(r) }()
+	select {
+	case err := <-done:
+		if err != nil || herr != ErrReentrantCall {
+			t.Fatal(err, herr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deadlock")
+	}
+	if _, err := p.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNextRefresh(t *testing.T) {
+	tr := newT()
+	p, _ := Initialize(tr, Options{PoolSize: 1, Timeout: time.Second, EvictionTest: true, EvictTestSchedule: 40 * time.Millisecond})
+	defer p.Close()
+	n1 := p.NextRefresh()
+	if d := time.Until(n1); d <= 0 || d > 40*time.Millisecond {
+		t.Fatal(d)
+	}
+	time.Sleep(60 * time.Millisecond) // one pass at 40
+	n2 := p.NextRefresh()
+	if d := n2.Sub(n1); d < 35*time.Millisecond || d > 45*time.Millisecond {
+		t.Fatal(d)
+	}
+	if n := atomic.LoadInt64(tr.evicts); n != 1 {
+		t.Fatal(n)
+	}
+	p.SkipNextRefresh()
+	p.SkipNextRefresh()
+	time.Sleep(40 * time.Millisecond) // pass at 80 skipped
+	if n := atomic.LoadInt64(tr.evicts); n != 1 {
+		t.Fatal(n)
+	}
+	time.Sleep(40 * time.Millisecond) // pass at 120 runs
+	if n := atomic.LoadInt64(tr.evicts); n != 2 {
+		t.Fatal(n)
+	}
+	if q, _ := Initialize(newT(), Options{PoolSize: 1}); !q.NextRefresh().IsZero() {
+		t.Fatal(q.NextRefresh())
+	}
+}
@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type hangRes struct {
+	*tres
+	hang chan struct{}
+	n    *int32
+}
+
+func (s *hangRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	if atomic.AddInt32(s.n, 1) == 1 {
+		return &hangRes{r.(*tres), s.hang, s.n}, nil
+	}
+	return &hangRes{r.(*tres), nil, s.n}, nil
+}
+func (s *hangRes) Evict() bool {
+	if s.hang != nil {
+		<-s.hang
+	}
+	return false
+}
+
+func TestRefreshTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	p, _ := Initialize(&hangRes{newT(), hang, new(int32)}, Options{PoolSize: 3, Timeout: time.Second,
+		EvictionTest: true, EvictTestSchedule: 20 * time.Millisecond, RefreshTimeout: 30 * time.Millisecond})
+	time.Sleep(100 * time.Millisecond)
+	var rs []Resource
+	for i := 0; i < 2; i++ /* one is stuck */ {
+		start := time.Now()
+		r, err := p.Acquire()
+		if err != nil || time.Since(start) > 100*time.Millisecond {
+			t.Fatal(i, err, time.Since(start))
+		}
+		rs = append(rs, r)
+	}
+	for _, r := range rs {
+		p.Release(r)
+	}
+	close(hang)
+	time.Sleep(100 * time.Millisecond)
+	if s := p.Stats(); s.Size != 3 || s.Idle != 3 {
+		t.Fatal(s)
+	}
+	p.Close()
+}
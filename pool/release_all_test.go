@@ -0,0 +1,39 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type failRes struct {
+	*tres
+	fail bool
+}
+
+func (s *failRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &failRes{tres: r.(*tres)}, nil
+}
+func (s *failRes) PreRelease() error {
+	if s.fail {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func TestReleaseAll(t *testing.T) {
+	p, _ := Initialize(&failRes{tres: newT()}, Options{PoolSize: 3, Timeout: time.Second})
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	rs[0].(*failRes).fail = true
+	if err := p.ReleaseAll(rs); err == nil || err.Error() != "boom" {
+		t.Fatal(err)
+	}
+	if p.Stats().Idle != 2 {
+		t.Fatal(p.Stats())
+	}
+}
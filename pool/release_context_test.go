@@ -0,0 +1,45 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type slowRelease struct {
+	*tres
+	slow bool
+}
+
+func (s *slowRelease) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &slowRelease{tres: r.(*tres)}, nil
+}
+func (s *slowRelease) PreRelease() error {
+	if s.slow {
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil
+}
+
+func TestReleaseContext(t *testing.T) {
+	p, _ := Initialize(&slowRelease{tres: newT()}, Options{PoolSize: 1, Timeout: time.Second})
+	r, _ := p.Acquire()
+	r.(*slowRelease).slow = true
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.ReleaseContext(ctx, r); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+	if !r.(*slowRelease).evicted.Load() {
+		t.Fatal("not evicted")
+	}
+	n, err := p.Acquire()
+	if err != nil || n == r {
+		t.Fatal(n, err)
+	}
+	if err := p.ReleaseContext(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(250 * time.Millisecond)
+}
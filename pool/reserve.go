@@ -0,0 +1,171 @@
+package pool
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Resources set aside for one user of the pool, e.g. a batch
+	// job that needs a known concurrency for its whole run.
+	// Created by Reserve.
+	Reservation struct {
+		p    *Pool             // pool the resources come from
+		held []Resource        // reserved resources not handed out
+		out  map[Resource]bool // resources handed out by Acquire
+		done bool              // closed
+		l    sync.Mutex        // guards the fields above
+	}
+)
+
+// Reserve n resources for a later burst. The resources are taken
+// from the pool straight away, so other acquirers can not starve
+// the holder of the reservation once it got it. Returns the error
+// of the first acquire that failed, giving back the resources
+// taken until then.
+//
+// Usage:
+//
+//	rv, err := p.Reserve(8)
+//	if err != nil { ... }
+//	defer rv.Close()
+//	r, err := rv.Acquire()
+//	...
+//	rv.Release(r)
+func (p *Pool) Reserve(n int) (*Reservation, error) {
+	rv := &Reservation{p: p, out: make(map[Resource]bool)}
+	for i := 0; i < n; i++ {
+		r, err := p.Acquire()
+		if err != nil {
+			if r != nil {
+				// Handed out along with a PostAcquire error
+				p.Release(r)
+			}
+			p.ReleaseAll(rv.held)
+			return nil, err
+		}
+		rv.held = append(rv.held, r)
+	}
+	p.l.Lock()
+	for _, r := range rv.held {
+		p.reserve(r, true)
+	}
+	p.l.Unlock()
+	p.logf(LogDebug, "reserved %d resources", n)
+	return rv, nil
+}
+
+// Acquire a reserved resource, or one from the pool once all of
+// them are handed out. A reserved resource goes through the checks
+// and hooks of Acquire; one that fails them is replaced and
+// another one acquired from the pool.
+func (rv *Reservation) Acquire() (Resource, error) {
+	p := rv.p
+	if p.reentrant() {
+		return nil, ErrReentrantCall
+	}
+	rv.l.Lock()
+	if n := len(rv.held); n > 0 && !rv.done {
+		r := rv.held[n-1]
+		rv.held = rv.held[:n-1]
+		rv.l.Unlock()
+		p.l.Lock()
+		p.reserve(r, false)
+		ok := !p.retire(r) && p.sound(r)
+		p.l.Unlock()
+		if !ok {
+			p.logf(LogDebug, "skipping broken reserved resource %v", r)
+			p.replace(r)
+			return p.Acquire()
+		}
+		v, _, err := p.ready(request{timeout: p.EffectiveTimeout()}, r, 0)
+		if v == r {
+			rv.l.Lock()
+			rv.out[r] = true
+			rv.l.Unlock()
+		}
+		return v, err
+	}
+	rv.l.Unlock()
+	return p.Acquire()
+}
+
+// Release a resource. A reserved resource goes back to the
+// reservation until Close, running the release hooks, any other
+// one back to the pool.
+func (rv *Reservation) Release(r Resource) error {
+	p := rv.p
+	rv.l.Lock()
+	if rv.out[r] && !rv.done {
+		rv.l.Unlock()
+		if p.reentrant() {
+			return ErrReentrantCall
+		}
+		if p.reclaimed(r) {
+			rv.l.Lock()
+			delete(rv.out, r)
+			rv.l.Unlock()
+			return ErrReclaimed
+		}
+		if err := p.hook(preRelease, r); err != nil {
+			return err
+		}
+		rv.l.Lock()
+		delete(rv.out, r)
+		if rv.done {
+			// Closed meanwhile
+			rv.l.Unlock()
+			return p.Release(r)
+		}
+		rv.held = append(rv.held, r)
+		rv.l.Unlock()
+		p.l.Lock()
+		p.reserve(r, true)
+		p.l.Unlock()
+		return p.hook(postRelease, r)
+	}
+	delete(rv.out, r)
+	rv.l.Unlock()
+	return p.Release(r)
+}
+
+// Reserved resources not handed out.
+func (rv *Reservation) Len() int {
+	rv.l.Lock()
+	defer rv.l.Unlock()
+	return len(rv.held)
+}
+
+// Give the unused reserved resources back to the pool. Reserved
+// resources still handed out go back to the pool on Release.
+func (rv *Reservation) Close() error {
+	rv.l.Lock()
+	if rv.done {
+		rv.l.Unlock()
+		return nil
+	}
+	rv.done = true
+	held := rv.held
+	rv.held = nil
+	rv.l.Unlock()
+	rv.p.l.Lock()
+	for _, r := range held {
+		rv.p.reserve(r, false)
+	}
+	rv.p.l.Unlock()
+	return rv.p.ReleaseAll(held)
+}
+
+// Internal function setting r aside for a Reservation, or handing
+// it out of one. Reserved resources are not reclaimed by
+// Options.MaxBorrowDuration, which counts from when they are
+// handed out.
+// Must be called with the lock held.
+func (p *Pool) reserve(r Resource, on bool) {
+	if m, ok := p.meta[identity(r)]; ok {
+		m.reserved = on
+		if !on {
+			m.borrowed = time.Now()
+		}
+	}
+}
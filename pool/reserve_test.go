@@ -0,0 +1,95 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReserve(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: 20 * time.Millisecond})
+	rv, err := p.Reserve(2)
+	if err != nil || rv.Len() != 2 || p.Stats().Idle != 1 {
+		t.Fatal(err, p.Stats())
+	}
+	a, _ := rv.Acquire()
+	b, _ := rv.Acquire()
+	c, err := rv.Acquire()
+	if err != nil || p.Stats().Idle != 0 {
+		t.Fatal(err)
+	}
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	rv.Release(a)
+	rv.Release(c)
+	if rv.Len() != 1 || p.Stats().Idle != 1 {
+		t.Fatal(rv.Len(), p.Stats())
+	}
+	rv.Close()
+	if p.Stats().Idle != 2 {
+		t.Fatal(p.Stats())
+	}
+	rv.Release(b)
+	if p.Stats().Idle != 3 {
+		t.Fatal(p.Stats())
+	}
+	if _, err := p.Reserve(4); err != ErrTimeout || p.Stats().Idle != 3 {
+		t.Fatal(err, p.Stats())
+	}
+}
+
+type reservedRes struct {
+	*tres
+	pre, rel *int64
+}
+
+func (h *reservedRes) Add() (Resource, error) {
+	r, _ := h.tres.Add()
+	return &reservedRes{r.(*tres), h.pre, h.rel}, nil
+}
+func (h *reservedRes) PreAcquire() error { atomic.AddInt64(h.pre, 1); return nil }
+func (h *reservedRes) PreRelease() error { atomic.AddInt64(h.rel, 1); return nil }
+
+func TestReserveChecks(t *testing.T) {
+	h := &reservedRes{newT(), new(int64), new(int64)}
+	p, _ := Initialize(h, Options{PoolSize: 3, Timeout: time.Second, AcquireOrder: AcquirePingTaken})
+	rv, _ := p.Reserve(2)
+	pre := atomic.LoadInt64(h.pre)
+	a, _ := rv.Acquire()
+	rv.Release(a)
+	if atomic.LoadInt64(h.pre) != pre+1 || atomic.LoadInt64(h.rel) != 1 {
+		t.Fatal(atomic.LoadInt64(h.pre), atomic.LoadInt64(h.rel))
+	}
+	// Failed Ping and Retire replace the reserved resource
+	a.(*reservedRes).ping.Store(false)
+	if r, err := rv.Acquire(); err != nil || r == a || !a.(*reservedRes).evicted.Load() {
+		t.Fatal("dead resource handed out", err)
+	}
+	b, _ := rv.Acquire()
+	rv.Release(b)
+	p.Retire(b)
+	if r, err := rv.Acquire(); err != nil || r == b {
+		t.Fatal("retired resource handed out", err)
+	}
+}
+
+func TestReserveBorrowDuration(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, MaxBorrowDuration: 20 * time.Millisecond})
+	rv, _ := p.Reserve(1)
+	time.Sleep(60 * time.Millisecond)
+	r, err := rv.Acquire()
+	if err != nil || r.(*tres).evicted.Load() {
+		t.Fatal("reserved resource reclaimed", err)
+	}
+	if err := rv.Release(r); err != nil {
+		t.Fatal(err)
+	}
+	// Counted from when it is handed out
+	r, _ = rv.Acquire()
+	time.Sleep(60 * time.Millisecond)
+	if err := rv.Release(r); err != ErrReclaimed || rv.Len() != 0 {
+		t.Fatal(err)
+	}
+	p.Close()
+}
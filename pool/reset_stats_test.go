@@ -0,0 +1,34 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetStats(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: 10 * time.Millisecond})
+	a, _ := p.Acquire()
+	b, _ := p.Acquire()
+	p.Acquire()
+	p.Release(b)
+	s := p.Stats()
+	if s.Acquires != 2 || s.Timeouts != 1 || s.InUse != 1 {
+		t.Fatal(s)
+	}
+	p.ResetStats()
+	s = p.Stats()
+	if s.Acquires != 0 || s.Timeouts != 0 || s.InUse != 1 || s.Idle != 1 || s.Size != 2 {
+		t.Fatal(s)
+	}
+	if m, _ := p.WaitTime(); m != 0 {
+		t.Fatal(m)
+	}
+	if f := p.FactoryStats(); f[0].Attempts != 0 {
+		t.Fatal(f)
+	}
+	p.Release(a)
+	p.Acquire()
+	if p.Stats().Acquires != 1 {
+		t.Fatal(p.Stats())
+	}
+}
@@ -0,0 +1,55 @@
+package pool
+
+import "errors"
+
+// Change the number of resources in the pool.
+// Growing creates the new resources straight away unless the
+// pool is lazy. Shrinking evicts idle resources at once and
+// acquired ones as they are released; resources dedicated to a
+// key are kept. The lock is held exclusively while the size
+// changes, so acquires and the refresh see either the old or the
+// new size. Returns the last error creating a resource.
+func (p *Pool) Resize(n int64) error {
+	if n < 0 {
+		return errors.New("Negative pool size")
+	}
+	p.l.Lock()
+	if p.closed {
+		p.l.Unlock()
+		return ErrPoolClosed
+	}
+	p.logf(LogInfo, "resizing pool from %d to %d", p.o.PoolSize, n)
+	p.o.PoolSize = n
+	var v []Resource
+	kept := p.idle[:0]
+	for _, r := range p.idle {
+		if p.s > n && !p.dedicated(r) {
+			p.s--
+			p.forget(r)
+			v = append(v, r)
+			continue
+		}
+		kept = append(kept, r)
+	}
+	p.idle = kept
+	var grow int64
+	if !p.o.Lazy && p.s < n {
+		grow = n - p.s
+		p.s = n
+	}
+	peer := p.peer()
+	p.l.Unlock()
+	for _, r := range v {
+		p.evict(r)
+	}
+	var err error
+	for ; grow > 0; grow-- {
+		r, e := p.create(p.ctx, peer)
+		if e != nil {
+			err = e
+			continue
+		}
+		p.fill(r)
+	}
+	return err
+}
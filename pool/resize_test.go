@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResize(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 4, Timeout: time.Second, AcquireOrder: AcquirePingTaken})
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if r, err := p.Acquire(); err == nil {
+					p.Stats()
+					if (i+j)%7 == 0 {
+						// Fails the Ping of the next Acquire, which retries
+						r.(*tres).ping.Store(false)
+					}
+					p.Release(r)
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		p.Resize(int64(1 + i%6))
+		time.Sleep(time.Millisecond)
+		if i%10 == 0 {
+			p.Reset()
+			p.SetFactory(newT().Add)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	p.Resize(3)
+	if s := p.Stats(); s.Size != 3 || s.Idle != 3 {
+		t.Fatal(s)
+	}
+	p.Resize(1)
+	if s := p.Stats(); s.Size != 1 {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,43 @@
+package pool
+
+import "time"
+
+type (
+	// Resource acquired with AcquireResult and how it was acquired.
+	Result struct {
+		Resource  Resource      // Resource acquired
+		Wait      time.Duration // Time AcquireResult took
+		Fresh     bool          // Created for this acquire, by a lazy pool or as overflow
+		Validated bool          // Pinged before being handed out, see Options.AcquireOrder
+		Age       time.Duration // Time since the resource was created
+		Uses      int64         // Times the resource was acquired, this acquire included
+	}
+)
+
+// Acquire a resource from the pool like Acquire, along with how
+// it was acquired. Saves a call to Stats or Utilization when the
+// caller adapts to the state of the pool.
+func (p *Pool) AcquireResult() (Result, error) {
+	var res Result
+	start := time.Now()
+	r, _, err := p.checkout(request{timeout: p.EffectiveTimeout(), res: &res})
+	res.Wait = time.Since(start)
+	if r == nil {
+		return Result{Wait: res.Wait}, err
+	}
+	res.Resource = r
+	p.l.RLock()
+	if m, ok := p.meta[identity(r)]; ok {
+		res.Age = time.Since(m.created)
+		res.Uses = m.uses
+	}
+	p.l.RUnlock()
+	return res, err
+}
+
+// Internal function noting that the resource acquired was pinged.
+func (q request) pinged() {
+	if q.res != nil {
+		q.res.Validated = true
+	}
+}
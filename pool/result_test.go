@@ -0,0 +1,20 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireResult(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 2, Lazy: true, AcquireOrder: AcquirePingIdle})
+	res, err := p.AcquireResult()
+	if err != nil || !res.Fresh || res.Validated || res.Uses != 1 || res.Resource == nil || res.Wait <= 0 {
+		t.Fatal(res, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	p.Release(res.Resource)
+	res2, err := p.AcquireResult()
+	if err != nil || res2.Fresh || !res2.Validated || res2.Uses != 2 || res2.Resource != res.Resource || res2.Age < 5*time.Millisecond {
+		t.Fatal(res2, err)
+	}
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetire(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: time.Second, MaxResourceErrors: 1})
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	p.ReleaseAll(rs)
+	x, y := rs[0], rs[1]
+	p.Retire(x)
+	p.ReportError(y, errors.New("broken"))
+	for i := 0; i < 3; i++ {
+		r, err := p.Acquire()
+		if err != nil || r == x || r == y {
+			t.Fatal(i, err)
+		}
+		defer p.Release(r)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !x.(*tres).evicted.Load() || !y.(*tres).evicted.Load() || p.Owns(x) || p.Owns(y) {
+		t.Fatal("kept")
+	}
+	if s := p.Stats(); s.Size != 3 {
+		t.Fatal(s)
+	}
+}
+
+func TestRetireShared(t *testing.T) {
+	p, _ := Initialize(sharedRes{newT()}, Options{PoolSize: 2, Timeout: time.Second})
+	x, _ := p.Acquire()
+	y, _ := p.Acquire()
+	if x != y {
+		t.Fatal("not shared")
+	}
+	p.Retire(x)
+	r, err := p.Acquire()
+	if err != nil || r == x {
+		t.Fatal("retired resource shared", err)
+	}
+	p.Release(x)
+	if s, _ := p.Acquire(); s == x {
+		t.Fatal("retired resource shared on release")
+	}
+	p.Release(y)
+	if !x.(sharedRes).evicted.Load() || p.Owns(x) {
+		t.Fatal("kept")
+	}
+	// Pinged before it is shared with AcquirePingIdle
+	q, _ := Initialize(sharedRes{newT()}, Options{PoolSize: 2, Timeout: time.Second, AcquireOrder: AcquirePingIdle})
+	x, _ = q.Acquire()
+	x.(sharedRes).ping.Store(false)
+	if r, _ := q.Acquire(); r == x {
+		t.Fatal("dead resource shared")
+	}
+}
@@ -0,0 +1,17 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTestOnReturn(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second, TestOnReturn: true})
+	a, _ := p.Acquire()
+	a.(*tres).ping.Store(false)
+	p.Release(a)
+	b, _ := p.Acquire()
+	if a == b || !a.(*tres).evicted.Load() || p.s != 1 {
+		t.Fatal()
+	}
+}
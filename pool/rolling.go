@@ -0,0 +1,78 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const rollWait = 10 * time.Millisecond // wait for acquired resources to come back during RollingRefresh
+
+// Replace every resource of the pool in waves of at most
+// parallelism idle ones, so all but parallelism of the idle
+// resources stay available throughout, e.g. to move every
+// connection to a new backend without an outage. Resources
+// acquired when it starts are replaced once released. Returns
+// the error of ctx once it is done, with the resources replaced
+// until then kept.
+func (p *Pool) RollingRefresh(ctx context.Context, parallelism int) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	p.l.Lock()
+	old := make(map[Resource]bool, len(p.meta))
+	for r := range p.meta {
+		old[r] = true
+	}
+	p.l.Unlock()
+	for n := 1; len(old) > 0; n++ {
+		p.l.Lock()
+		if p.closed {
+			p.l.Unlock()
+			return ErrPoolClosed
+		}
+		for r := range old {
+			if _, ok := p.meta[r]; !ok {
+				// Evicted in the meantime
+				delete(old, r)
+			}
+		}
+		var wave []Resource
+		kept := p.idle[:0]
+		for _, r := range p.idle {
+			if len(wave) < parallelism && old[identity(r)] {
+				delete(old, identity(r))
+				wave = append(wave, r)
+				continue
+			}
+			kept = append(kept, r)
+		}
+		p.idle = kept
+		p.l.Unlock()
+		if len(wave) == 0 {
+			if len(old) == 0 {
+				break
+			}
+			select {
+			case <-time.After(rollWait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+		p.logf(LogDebug, "rolling refresh wave %d replacing %d resources", n, len(wave))
+		var wg sync.WaitGroup
+		for _, r := range wave {
+			wg.Add(1)
+			go func(r Resource) {
+				defer wg.Done()
+				p.replace(r)
+			}(r)
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
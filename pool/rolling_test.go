@@ -0,0 +1,71 @@
+package pool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type rollRes struct {
+	*tres
+	idle func() int64
+	low  *int64
+}
+
+func (s *rollRes) Add() (Resource, error) {
+	if s.idle != nil {
+		n := s.idle()
+		for {
+			l := atomic.LoadInt64(s.low)
+			if n >= l || atomic.CompareAndSwapInt64(s.low, l, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	r, _ := s.tres.Add()
+	return &rollRes{tres: r.(*tres), idle: s.idle, low: s.low}, nil
+}
+
+func TestRollingRefresh(t *testing.T) {
+	low := int64(100)
+	f := &rollRes{tres: newT(), low: &low}
+	p, _ := Initialize(f, Options{PoolSize: 10, Timeout: time.Second})
+	var old []Resource
+	for i := 0; i < 10; i++ {
+		r, _ := p.Acquire()
+		old = append(old, r)
+	}
+	held := old[9]
+	for _, r := range old[:9] {
+		p.Release(r)
+	}
+	f.idle = func() int64 { return p.Stats().Idle }
+	// the factory copy used by the pool is f itself via r.Add
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.Release(held)
+	}()
+	if err := p.RollingRefresh(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	if p.Stats().Idle != 10 || p.Stats().Size != 10 {
+		t.Fatal(p.Stats())
+	}
+	for _, r := range old {
+		if p.Owns(r) || !r.(*rollRes).evicted.Load() {
+			t.Fatal("old kept", r)
+		}
+	}
+	if low < 9-3 || low == 100 {
+		t.Fatal(low)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r, _ := p.Acquire()
+	cancel()
+	if err := p.RollingRefresh(ctx, 20); err != context.Canceled {
+		t.Fatal(err)
+	}
+	p.Release(r)
+}
@@ -0,0 +1,50 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealthScore(t *testing.T) {
+	b := newT()
+	b.evict = true
+	p, _ := Initialize(b, Options{PoolSize: 3, Timeout: time.Second, MaxEvictPerPass: 1})
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	p.ReportError(rs[2], errors.New("x"))
+	for _, r := range rs {
+		p.Release(r)
+	}
+	h := p.Health()
+	if h[rs[0]] != 1 || h[rs[2]] != 0 {
+		t.Fatal(h)
+	}
+	p.refreshPool(nil)
+	if !rs[2].(*tres).evicted.Load() || rs[0].(*tres).evicted.Load() || rs[1].(*tres).evicted.Load() {
+		t.Fatal("wrong one evicted")
+	}
+}
+
+func TestRefreshKeepsOrder(t *testing.T) {
+	b := newT()
+	p, _ := Initialize(b, Options{PoolSize: 3, Timeout: time.Second, LIFO: true})
+	var rs []Resource
+	for i := 0; i < 3; i++ {
+		r, _ := p.Acquire()
+		rs = append(rs, r)
+	}
+	p.ReportError(rs[2], errors.New("x"))
+	for _, r := range rs {
+		p.Release(r)
+	}
+	p.refreshPool(nil)
+	for i := 2; i >= 0; i-- {
+		if r, _ := p.Acquire(); r != rs[i] {
+			t.Fatal("order changed at", i)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetTimeout(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second})
+	r, _ := p.Acquire()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); p.SetTimeout(20 * time.Millisecond); p.EffectiveTimeout() }()
+	}
+	wg.Wait()
+	start := time.Now()
+	if _, err := p.Acquire(); err != ErrTimeout || time.Since(start) > 500*time.Millisecond {
+		t.Fatal(err)
+	}
+	p.Release(r)
+	tr := newT()
+	q, _ := Initialize(tr, Options{PoolSize: 1, EvictionTest: true, EvictTestSchedule: time.Hour})
+	q.SetEvictSchedule(10 * time.Millisecond)
+	if time.Until(q.NextRefresh()) > time.Second {
+		t.Fatal(q.NextRefresh())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if time.Until(q.NextRefresh()) > time.Second {
+		t.Fatal()
+	}
+}
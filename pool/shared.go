@@ -0,0 +1,94 @@
+package pool
+
+import "time"
+
+type (
+	// Resource that can serve several acquirers at once, e.g. a
+	// connection multiplexing streams. The pool hands it out to up
+	// to MaxConcurrency acquirers before taking another resource;
+	// it goes back to the idle resources once all of them released
+	// it.
+	SharedResource interface {
+		Resource
+		MaxConcurrency() int // Most acquirers served at once
+	}
+)
+
+// Internal function taking a shared resource that can serve
+// another acquirer, checked with sound as an idle one is. One
+// that fails is no longer shared and is replaced once its last
+// holder releases it. Returns nil if there is none.
+// Must be called with the lock held.
+func (p *Pool) reuse() Resource {
+	for i := 0; i < len(p.open); i++ {
+		r := p.open[i]
+		if p.dedicated(r) {
+			continue
+		}
+		if p.sound(r) {
+			return r
+		}
+		p.logf(LogDebug, "skipping broken resource %v", r)
+		p.reopen(r, false)
+		i--
+	}
+	return nil
+}
+
+// Internal function counting a new holder of r if it is a shared
+// resource.
+// Must be called with the lock held.
+func (p *Pool) cohold(r Resource, m *record) {
+	s, ok := r.(SharedResource)
+	if !ok || s.MaxConcurrency() <= 1 {
+		return
+	}
+	m.shares++
+	p.reopen(r, m.shares < s.MaxConcurrency())
+}
+
+// Internal function giving back one share of a shared resource.
+// The resource goes to the next waiter if there is one. Returns
+// false if r was held only once, in which case it is released as
+// any other resource.
+// Must be called with the lock held.
+func (p *Pool) unshare(r Resource) bool {
+	m, ok := p.meta[identity(r)]
+	if !ok || m.shares <= 1 {
+		if ok {
+			m.shares = 0
+		}
+		p.reopen(r, false)
+		return false
+	}
+	m.shares--
+	if p.broken(r) || !valid(r) {
+		// Left to its other holders until the last one releases it
+		return true
+	}
+	p.reopen(r, true)
+	if i := p.next(r); i >= 0 {
+		w := p.w[i]
+		p.w = append(p.w[:i], p.w[i+1:]...)
+		p.lend(r, w.class, time.Since(w.t))
+		w.c <- r
+	}
+	return true
+}
+
+// Internal function adding r to or removing it from the shared
+// resources that can serve another acquirer.
+// Must be called with the lock held.
+func (p *Pool) reopen(r Resource, open bool) {
+	for i, v := range p.open {
+		if v == r {
+			if !open {
+				p.open = append(p.open[:i], p.open[i+1:]...)
+			}
+			return
+		}
+	}
+	if open {
+		p.open = append(p.open, r)
+	}
+}
@@ -0,0 +1,54 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type sharedRes struct{ *tres }
+
+func (s sharedRes) Add() (Resource, error) { r, _ := s.tres.Add(); return sharedRes{r.(*tres)}, nil }
+func (s sharedRes) MaxConcurrency() int    { return 3 }
+
+func TestSharedResource(t *testing.T) {
+	p, _ := Initialize(sharedRes{newT()}, Options{PoolSize: 2, Timeout: 20 * time.Millisecond})
+	var rs []Resource
+	for i := 0; i < 6; i++ {
+		r, err := p.Acquire()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		rs = append(rs, r)
+	}
+	if rs[0] != rs[1] || rs[1] != rs[2] || rs[3] != rs[4] || rs[0] == rs[3] {
+		t.Fatal(rs)
+	}
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	p.Release(rs[0])
+	r, err := p.Acquire()
+	if err != nil || r != rs[0] || p.Stats().Idle != 0 {
+		t.Fatal(err)
+	}
+	for _, r := range append(rs[1:], r) {
+		p.Release(r)
+	}
+	if p.Stats().Idle != 2 {
+		t.Fatal(p.Stats())
+	}
+	// waiter handed a share
+	var hold []Resource
+	for i := 0; i < 6; i++ {
+		r, _ := p.Acquire()
+		hold = append(hold, r)
+	}
+	got := make(chan Resource)
+	p.o.Timeout = time.Second
+	go func() { r, _ := p.Acquire(); got <- r }()
+	time.Sleep(10 * time.Millisecond)
+	p.Release(hold[5])
+	if <-got != hold[5] {
+		t.Fatal()
+	}
+}
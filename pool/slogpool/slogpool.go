@@ -0,0 +1,60 @@
+// Structured logging of pool events.
+//
+// Bridges the pool's event channel into a log/slog logger,
+// each event becoming one record with the resource ID, event
+// type and duration as attributes.
+//
+// Example:
+//
+//	events := slogpool.Events(ctx, slog.Default(), 64)
+//	p, _ := pool.Initialize(r, pool.Options{PoolSize: 10,
+//		Timeout: time.Second,
+//		Events:  events})
+package slogpool
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/avarghes1/go_pool/pool"
+)
+
+// Log the events received on c to l until c is closed or ctx is done.
+// Timeouts are logged as warnings, evictions as info and everything
+// else at debug level.
+func Log(ctx context.Context, l *slog.Logger, c <-chan pool.Event) {
+	for {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				return
+			}
+			l.LogAttrs(ctx, level(e.Type), "pool "+e.Type.String(),
+				slog.String("event", e.Type.String()),
+				slog.Uint64("resource", e.ID),
+				slog.Duration("duration", e.Duration),
+				slog.Time("at", e.Time))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Start logging events to l in the background until ctx is done.
+// Returns the channel to use as Options.Events, buffered to hold
+// size events.
+func Events(ctx context.Context, l *slog.Logger, size int) chan<- pool.Event {
+	c := make(chan pool.Event, size)
+	go Log(ctx, l, c)
+	return c
+}
+
+func level(t pool.EventType) slog.Level {
+	switch t {
+	case pool.EventTimeout:
+		return slog.LevelWarn
+	case pool.EventEvict:
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
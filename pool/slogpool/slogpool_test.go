@@ -0,0 +1,102 @@
+package slogpool
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avarghes1/go_pool/pool"
+)
+
+type res struct{}
+
+func (res) Add() (pool.Resource, error) { return &res2{}, nil }
+func (res) Ping() bool                  { return true }
+func (res) Evict() bool                 { return true }
+
+type res2 struct {
+	res
+	n int
+}
+
+// Handler keeping the records it is given.
+type handler struct {
+	l  sync.Mutex
+	rs []slog.Record
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *handler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *handler) WithGroup(string) slog.Handler            { return h }
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	h.l.Lock()
+	h.rs = append(h.rs, r)
+	h.l.Unlock()
+	return nil
+}
+
+// First record of event e, waiting for it to be logged.
+func (h *handler) find(t *testing.T, e string) slog.Record {
+	for end := time.Now().Add(time.Second); time.Now().Before(end); time.Sleep(time.Millisecond) {
+		h.l.Lock()
+		for _, r := range h.rs {
+			if r.Message == "pool "+e {
+				h.l.Unlock()
+				return r
+			}
+		}
+		h.l.Unlock()
+	}
+	t.Fatal("not logged:", e)
+	return slog.Record{}
+}
+
+func attrs(r slog.Record) map[string]slog.Value {
+	m := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+func TestLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := &handler{}
+	p, _ := pool.Initialize(res{}, pool.Options{PoolSize: 1, Timeout: 20 * time.Millisecond,
+		Events: Events(ctx, slog.New(h), 64)})
+	r, _ := p.Acquire()
+	if _, err := p.Acquire(); err != pool.ErrTimeout {
+		t.Fatal(err)
+	}
+	p.Release(r)
+	p.Close()
+	for _, c := range []struct {
+		event    string
+		level    slog.Level
+		resource bool
+		duration time.Duration
+	}{
+		{"acquire", slog.LevelDebug, true, 0},
+		{"timeout", slog.LevelWarn, false, 20 * time.Millisecond},
+		{"evict", slog.LevelInfo, true, 0},
+	} {
+		rec := h.find(t, c.event)
+		a := attrs(rec)
+		if rec.Level != c.level || a["event"].String() != c.event {
+			t.Fatal(c.event, rec.Level, a)
+		}
+		if id := a["resource"].Uint64(); (id != 0) != c.resource {
+			t.Fatal(c.event, "resource", id)
+		}
+		if c.duration > 0 && a["duration"].Duration() != c.duration {
+			t.Fatal(c.event, "duration", a["duration"])
+		}
+		if a["at"].Kind() != slog.KindTime || a["at"].Time().IsZero() {
+			t.Fatal(c.event, "at", a["at"])
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireSpecific(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: 200 * time.Millisecond})
+	a, _ := p.Acquire()
+	p.Release(a)
+	for i := 0; i < 3; i++ {
+		b, err := p.AcquireSpecific(a)
+		if err != nil || b != a {
+			t.Fatal(err)
+		}
+		ch := make(chan Resource)
+		go func() { r, _ := p.AcquireSpecific(a); ch <- r }()
+		go func() { r, _ := p.Acquire(); p.Release(r) }()
+		time.Sleep(20 * time.Millisecond)
+		p.Release(b)
+		if <-ch != a {
+			t.Fatal()
+		}
+		p.Release(a)
+	}
+	if _, err := p.AcquireSpecific(newT()); err != ErrUnknownResource {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,41 @@
+package pool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSpinAttempts(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 50 * time.Millisecond, SpinAttempts: 100})
+	r, _ := p.Acquire()
+	s := time.Now()
+	if _, err := p.Acquire(); err != ErrTimeout {
+		t.Fatal(err)
+	}
+	if d := time.Since(s); d > 500*time.Millisecond {
+		t.Fatal(d)
+	}
+	go func() { time.Sleep(time.Microsecond); p.Release(r) }()
+	if _, err := p.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkSpinAttempts(b *testing.B) {
+	for _, n := range []int{0, 100} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			p, _ := Initialize(newT(), Options{PoolSize: 2, Timeout: time.Second, SpinAttempts: n})
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					r, err := p.Acquire()
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					p.Release(r)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStarvation(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second})
+	a, _ := p.Acquire()
+	done := make(chan Resource, 2)
+	go func() { r, _ := p.Acquire(); done <- r }()
+	time.Sleep(50 * time.Millisecond)
+	go func() { r, _ := p.Acquire(); done <- r }()
+	time.Sleep(20 * time.Millisecond)
+	if s := p.Starvation(); s < 50*time.Millisecond || s > 200*time.Millisecond {
+		t.Fatal(s)
+	}
+	p.Release(a)
+	b := <-done
+	p.Release(b)
+	<-done
+	if p.Starvation() != 0 {
+		t.Fatal()
+	}
+	mx, avg := p.WaitTime()
+	if mx < 50*time.Millisecond || avg == 0 {
+		t.Fatal(mx, avg)
+	}
+	// timeout path
+	p2, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 10 * time.Millisecond})
+	p2.Acquire()
+	if _, err := p2.Acquire(); err == nil || err.Error() != "Timeout" {
+		t.Fatal(err)
+	}
+	if len(p2.w) != 0 {
+		t.Fatal("waiter leaked")
+	}
+	p2.Close()
+}
@@ -0,0 +1,91 @@
+package pool
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	// Saved state of a pool, see ExportState.
+	state struct {
+		PoolSize          int64           `json:"pool_size"`
+		Timeout           time.Duration   `json:"timeout"`
+		EvictionTest      bool            `json:"eviction_test"`
+		EvictTestSchedule time.Duration   `json:"evict_test_schedule"`
+		Lazy              bool            `json:"lazy"`
+		MaxOverflow       int64           `json:"max_overflow"`
+		MaxLifetime       time.Duration   `json:"max_lifetime"`
+		MinIdle           int64           `json:"min_idle"`
+		Acquires          int64           `json:"acquires"`
+		Timeouts          int64           `json:"timeouts"`
+		Resources         []resourceState `json:"resources"`
+	}
+	// Saved metadata of a resource.
+	resourceState struct {
+		Created time.Time `json:"created"`
+		Uses    int64     `json:"uses"`
+		Errors  int       `json:"errors"`
+	}
+)
+
+// Serialize the configuration of the pool and what it knows about
+// its resources, but not the resources themselves, for
+// InitializeState to pick up after a restart.
+func (p *Pool) ExportState() ([]byte, error) {
+	p.l.RLock()
+	s := state{
+		PoolSize:          p.o.PoolSize,
+		Timeout:           p.o.Timeout,
+		EvictionTest:      p.o.EvictionTest,
+		EvictTestSchedule: p.o.EvictTestSchedule,
+		Lazy:              p.o.Lazy,
+		MaxOverflow:       p.o.MaxOverflow,
+		MaxLifetime:       p.o.MaxLifetime,
+		MinIdle:           p.o.MinIdle,
+		Acquires:          p.ws.n,
+		Timeouts:          p.ws.t,
+	}
+	for _, m := range p.meta {
+		s.Resources = append(s.Resources, resourceState{Created: m.created, Uses: m.uses, Errors: m.total})
+	}
+	p.l.RUnlock()
+	return json.Marshal(s)
+}
+
+// Initialize a pool from the state saved by ExportState.
+// The saved configuration overrides that of o; what can not be
+// saved, like callbacks, comes from o. The new resources take
+// over the ages and counters of the saved ones, so Stats carry
+// on and Options.MaxLifetime expiry stays staggered.
+func InitializeState(r Resource, o Options, data []byte) (*Pool, error) {
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	o.PoolSize = s.PoolSize
+	o.Timeout = s.Timeout
+	o.EvictionTest = s.EvictionTest
+	o.EvictTestSchedule = s.EvictTestSchedule
+	o.Lazy = s.Lazy
+	o.MaxOverflow = s.MaxOverflow
+	o.MaxLifetime = s.MaxLifetime
+	o.MinIdle = s.MinIdle
+	p, err := Initialize(r, o)
+	if err != nil {
+		return nil, err
+	}
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.ws.n = s.Acquires
+	p.ws.t = s.Timeouts
+	for i, v := range p.idle {
+		if i == len(s.Resources) {
+			break
+		}
+		m := p.meta[identity(v)]
+		m.created = s.Resources[i].Created
+		m.uses = s.Resources[i].Uses
+		m.total = s.Resources[i].Errors
+	}
+	return p, nil
+}
@@ -0,0 +1,39 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExportState(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 3, Timeout: 2 * time.Second, MaxLifetime: time.Hour})
+	r, _ := p.Acquire()
+	p.Release(r)
+	p.l.Lock()
+	old := time.Now().Add(-30 * time.Minute).Round(0)
+	for _, m := range p.meta {
+		m.created = old
+	}
+	p.l.Unlock()
+	b, err := p.ExportState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := InitializeState(newT(), Options{}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.o.PoolSize != 3 || q.o.Timeout != 2*time.Second || q.o.MaxLifetime != time.Hour || q.Stats().Acquires != 1 || q.Stats().Size != 3 {
+		t.Fatal(q.o, q.Stats())
+	}
+	var uses int64
+	for _, m := range q.meta {
+		if !m.created.Equal(old) {
+			t.Fatal(m.created, old)
+		}
+		uses += m.uses
+	}
+	if uses != 1 {
+		t.Fatal(uses)
+	}
+}
@@ -0,0 +1,20 @@
+package pool
+
+type (
+	// Resource keeping local state that can go stale, e.g. a cache
+	// of prepared statements. Every acquire, AcquireWithKey and
+	// AcquireSpecific included, checks Valid, which must be cheap
+	// and not go over the network, before any Ping, and replaces a
+	// resource whose state is no longer valid.
+	StatefulResource interface {
+		Resource
+		Valid() bool // Check the local state of the resource
+	}
+)
+
+// Internal function checking the local state of r if it keeps
+// one.
+func valid(r Resource) bool {
+	s, ok := r.(StatefulResource)
+	return !ok || s.Valid()
+}
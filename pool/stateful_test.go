@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type statefulRes struct {
+	*tres
+	ok *atomic.Bool
+}
+
+func (s *statefulRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	ok := new(atomic.Bool)
+	ok.Store(true)
+	return &statefulRes{r.(*tres), ok}, nil
+}
+func (s *statefulRes) Valid() bool { return s.ok.Load() }
+
+func TestStatefulResource(t *testing.T) {
+	p, _ := Initialize(&statefulRes{tres: newT()}, Options{PoolSize: 1, Timeout: time.Second})
+	r, _ := p.Acquire()
+	p.Release(r)
+	r.(*statefulRes).ok.Store(false)
+	n, err := p.Acquire()
+	if err != nil || n == r {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !r.(*statefulRes).evicted.Load() || p.Owns(r) {
+		t.Fatal("kept")
+	}
+}
+
+func TestStatefulResourceKeyed(t *testing.T) {
+	p, _ := Initialize(&statefulRes{tres: newT()}, Options{PoolSize: 2, Timeout: time.Second, PrewarmKeys: []string{"a"}})
+	r, _ := p.AcquireWithKey("a")
+	p.Release(r)
+	r.(*statefulRes).ok.Store(false)
+	if n, err := p.AcquireWithKey("a"); err != nil || n == r {
+		t.Fatal("stale resource lent", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !r.(*statefulRes).evicted.Load() || p.Owns(r) {
+		t.Fatal("kept")
+	}
+	// Checked on AcquireSpecific too
+	s, _ := p.AcquireWithKey("a")
+	p.Release(s)
+	s.(*statefulRes).ok.Store(false)
+	if _, err := p.AcquireSpecific(s); err != ErrUnknownResource {
+		t.Fatal(err)
+	}
+}
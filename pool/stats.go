@@ -0,0 +1,53 @@
+package pool
+
+type (
+	// Snapshot of the state of a pool.
+	Stats struct {
+		Size     int64 // Resources owned by the pool
+		Idle     int64 // Resources waiting to be acquired
+		InUse    int64 // Resources currently acquired
+		Waiters  int64 // Acquirers blocked waiting for a resource
+		Fallback bool  // Resources are created by Options.FallbackFactory
+		Acquires int64 // Resources handed out since Initialize
+		Timeouts int64 // Acquires that timed out since Initialize
+	}
+)
+
+// Stats of the pool.
+func (p *Pool) Stats() Stats {
+	fallback := p.fallback()
+	p.l.RLock()
+	defer p.l.RUnlock()
+	return Stats{
+		Size:     p.s,
+		Idle:     int64(len(p.idle)),
+		InUse:    p.s - int64(len(p.idle)+len(p.parked)),
+		Waiters:  int64(len(p.w)),
+		Fallback: fallback,
+		Acquires: p.ws.n,
+		Timeouts: p.ws.t,
+	}
+}
+
+// Change since an earlier snapshot prev.
+// Counters, like Acquires, are the difference, gauges, like Size,
+// the value of s. Divide the counters by the time between the
+// snapshots for rates.
+func (s Stats) Sub(prev Stats) Stats {
+	s.Acquires -= prev.Acquires
+	s.Timeouts -= prev.Timeouts
+	return s
+}
+
+// Zero the counters of the pool, like Stats.Acquires, WaitTime
+// and FactoryStats, to measure from now on, e.g. after a deploy.
+// Gauges, like Stats.Size, and the recent wait times behind
+// Options.AdaptiveTimeout are kept.
+func (p *Pool) ResetStats() {
+	p.l.Lock()
+	p.ws.n, p.ws.t, p.ws.sum, p.ws.max = 0, 0, 0, 0
+	p.l.Unlock()
+	p.fa.l.Lock()
+	p.fa.st = nil
+	p.fa.l.Unlock()
+}
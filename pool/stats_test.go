@@ -0,0 +1,12 @@
+package pool
+
+import "testing"
+
+func TestStatsSub(t *testing.T) {
+	a := Stats{Size: 2, Idle: 1, InUse: 1, Acquires: 10, Timeouts: 3}
+	b := Stats{Size: 4, Idle: 0, InUse: 4, Waiters: 2, Acquires: 25, Timeouts: 4, Fallback: true}
+	d := b.Sub(a)
+	if d != (Stats{Size: 4, InUse: 4, Waiters: 2, Acquires: 15, Timeouts: 1, Fallback: true}) {
+		t.Fatal(d)
+	}
+}
@@ -0,0 +1,28 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxEvictPerPass(t *testing.T) {
+	b := newT()
+	b.evict = true
+	p, _ := Initialize(b, Options{PoolSize: 6, Timeout: time.Second, MaxEvictPerPass: 2})
+	orig := append([]Resource(nil), p.idle...)
+	for pass := 1; pass <= 3; pass++ {
+		p.refreshPool(nil)
+		if e := atomic.LoadInt64(b.evicts); e != int64(2*pass) {
+			t.Fatal(pass, e)
+		}
+	}
+	for _, r := range orig {
+		if !r.(*tres).evicted.Load() {
+			t.Fatal("not all replaced")
+		}
+	}
+	if p.Stats().Size != 6 {
+		t.Fatal(p.Stats())
+	}
+}
@@ -0,0 +1,23 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTriggerRefresh(t *testing.T) {
+	b := newT()
+	b.evict = true
+	p, _ := Initialize(b, Options{PoolSize: 2, Timeout: time.Second, EvictionTest: true, EvictTestSchedule: time.Hour})
+	p.TriggerRefresh()
+	p.TriggerRefresh()
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(b.evicts) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal(*b.evicts)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	p.Close()
+}
@@ -0,0 +1,24 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireOrCreate(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second})
+	a, _ := p.Acquire()
+	b, err := p.AcquireOrCreate()
+	if err != nil || b == a || p.Stats().Size != 2 {
+		t.Fatal(err, p.Stats())
+	}
+	p.Release(b)
+	if !b.(*tres).evicted.Load() || p.Stats().Size != 1 {
+		t.Fatal(p.Stats())
+	}
+	p.Release(a)
+	c, _ := p.AcquireOrCreate()
+	if c != a {
+		t.Fatal("not idle one")
+	}
+}
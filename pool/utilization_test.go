@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUtilization(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: time.Second})
+	time.Sleep(30 * time.Millisecond)
+	r, _ := p.Acquire()
+	time.Sleep(50 * time.Millisecond)
+	idle, busy := p.Utilization(r)
+	if idle < 30*time.Millisecond || idle > 45*time.Millisecond || busy < 50*time.Millisecond {
+		t.Fatal(idle, busy)
+	}
+	p.Release(r)
+	time.Sleep(20 * time.Millisecond)
+	i2, b2 := p.Utilization(r)
+	if b2 < 50*time.Millisecond || b2 > 70*time.Millisecond || i2 < idle+20*time.Millisecond {
+		t.Fatal(i2, b2)
+	}
+	if i, b := p.Utilization(newT()); i != 0 || b != 0 {
+		t.Fatal(i, b)
+	}
+}
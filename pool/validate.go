@@ -0,0 +1,75 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Compile time check that r implements Resource.
+// Equivalent to declaring
+//
+//	var _ pool.Resource = (*Testresource)(nil)
+//
+// next to the resource type.
+func AssertResource(r Resource) {}
+
+// Validate a resource before putting it in a pool.
+// Creates a resource with Add, checks it with Ping and runs
+// every hook it implements once, in the order a pool calls them. The
+// created resource is evicted afterwards. All problems found,
+// including panics, are returned together.
+func ValidateResource(r Resource) error {
+	if r == nil {
+		return errors.New("Resource is nil")
+	}
+	var errs []error
+	var n Resource
+	if err := try("Add", func() (err error) {
+		n, err = r.Add()
+		return err
+	}); err != nil {
+		return err
+	}
+	if n == nil {
+		return errors.New("Add returned a nil resource")
+	}
+	defer n.Evict()
+	if !trackable(n) {
+		errs = append(errs, ErrIncomparable)
+	}
+	if err := try("Ping", func() error {
+		if !n.Ping() {
+			return errors.New("resource is not valid")
+		}
+		return nil
+	}); err != nil {
+		errs = append(errs, err)
+	}
+	for _, h := range []struct {
+		name string
+		f    func() error
+	}{
+		{"PreAcquire", func() error { return preAcquire(n) }},
+		{"PostAcquire", func() error { return postAcquire(n) }},
+		{"PreRelease", func() error { return preRelease(n) }},
+		{"PostRelease", func() error { return postRelease(n) }},
+	} {
+		if err := try(h.name, h.f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Internal function calling f, turning a panic into an error.
+func try(name string, f func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%s panicked: %v", name, v)
+		}
+	}()
+	if err = f(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
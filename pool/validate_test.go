@@ -0,0 +1,25 @@
+package pool
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type bres struct{ *tres }
+
+func (b *bres) Add() (Resource, error) { return &bres{newT()}, nil }
+func (b *bres) Ping() bool             { return false }
+func (b *bres) PreAcquire() error      { return errors.New("x") }
+func (b *bres) PostRelease() error     { panic("boom") }
+
+func TestValidateResource(t *testing.T) {
+	AssertResource(newT())
+	if err := ValidateResource(newT()); err != nil {
+		t.Fatal(err)
+	}
+	err := ValidateResource(&bres{newT()})
+	if err == nil || !strings.Contains(err.Error(), "Ping") || !strings.Contains(err.Error(), "PreAcquire") || !strings.Contains(err.Error(), "panicked") {
+		t.Fatal(err)
+	}
+}
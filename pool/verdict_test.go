@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReleaseWithVerdict(t *testing.T) {
+	tr := newT()
+	p, _ := Initialize(tr, Options{PoolSize: 1, Timeout: time.Second})
+	r, _ := p.Acquire()
+	if err := p.ReleaseWithVerdict(r, true); err != nil || *tr.evicts != 0 {
+		t.Fatal(err)
+	}
+	r, _ = p.Acquire()
+	if err := p.ReleaseWithVerdict(r, false); err != nil {
+		t.Fatal(err)
+	}
+	if !r.(*tres).evicted.Load() || p.Owns(r) {
+		t.Fatal("kept")
+	}
+	n, _ := p.Acquire()
+	if n == r || p.Stats().Idle != 0 {
+		t.Fatal(n)
+	}
+}
@@ -0,0 +1,289 @@
+package pool
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+type (
+	// An acquirer blocked waiting for a resource.
+	waiter struct {
+		c     chan Resource // receives the handed over resource
+		t     time.Time     // when the wait started
+		class int           // priority class
+		want  Resource      // the only resource accepted, nil for any
+		key   string        // key whose dedicated resource is also accepted, see AcquireWithKey
+		err   error         // returned once c is closed without a resource
+	}
+	// Parameters of an acquire.
+	request struct {
+		timeout time.Duration   // how long to wait for a resource
+		class   int             // priority class
+		want    Resource        // the only resource accepted, nil for any
+		ctx     context.Context // cancels the acquire, nil for none
+		graced  *bool           // set once the grace of AcquireBestEffort is used, nil for none
+		force   bool            // create a resource beyond PoolSize rather than wait
+		tries   int             // resources that failed PostAcquire
+		below   float64         // utilization at which the acquire fails, 0 for any
+		res     *Result         // filled in with how the resource was acquired, nil for none
+		zone    string          // zone preferred with AcquireZone, empty for any
+		key     string          // key whose dedicated resource is taken if idle, see AcquireWithKey
+		nowait  bool            // fail with ErrPoolEmpty rather than wait
+		spilled bool            // Options.OverflowPool tried already
+	}
+	// Order in which blocked acquirers are served.
+	QueueDiscipline int
+	// Wait times of acquirers.
+	waitStats struct {
+		n      int64           // number of acquires
+		sum    time.Duration   // total time waited
+		max    time.Duration   // longest wait
+		t      int64           // acquires that timed out
+		recent []time.Duration // recent waits, ring buffer
+		rn     int             // oldest wait in recent once full
+	}
+)
+
+const waitSamples = 128 // recent waits kept for Options.AdaptiveTimeout
+
+const (
+	QueueFIFO   QueueDiscipline = iota // Longest waiting first
+	QueueLIFO                          // Most recent first
+	QueueRandom                        // Any waiter, picked at random
+)
+
+func newWaiter(q request) *waiter {
+	return &waiter{c: make(chan Resource, 1), t: time.Now(), class: q.class, want: q.want, key: q.key}
+}
+
+// Internal function picking the waiter to hand r to.
+// Waiters for exactly r, or for the key r is dedicated to, go
+// first, then the highest class, or the class furthest behind its
+// share with Options.ClassWeights, then Options.QueueDiscipline
+// decides. Returns -1 if no waiter
+// accepts r.
+// Must be called with the lock held.
+func (p *Pool) next(r Resource) int {
+	share, fair := p.share()
+	key := ""
+	if p.dedicated(r) {
+		key = p.keyOf(r)
+	}
+	exact := func(w *waiter) bool { return w.want != nil || key != "" && w.key == key }
+	var c []int
+	for i, w := range p.w {
+		if w.want != nil && w.want != r || w.want == nil && key != "" && w.key != key {
+			continue
+		}
+		if fair && !exact(w) && w.class != share {
+			continue
+		}
+		if len(c) > 0 {
+			v := p.w[c[0]]
+			if exact(w) != exact(v) {
+				if exact(v) {
+					continue
+				}
+			} else if w.class < v.class {
+				continue
+			} else if w.class == v.class {
+				c = append(c, i)
+				continue
+			}
+		}
+		c = append(c[:0], i)
+	}
+	if len(c) == 0 {
+		return -1
+	}
+	switch p.o.QueueDiscipline {
+	case QueueLIFO:
+		return c[len(c)-1]
+	case QueueRandom:
+		return c[rand.Intn(len(c))]
+	}
+	return c[0]
+}
+
+// Internal function picking the waiting class furthest behind its
+// share of Options.ClassWeights. Classes without a weight are
+// only served when no weighted class waits. Returns false without
+// weights or general waiters.
+// Must be called with the lock held.
+func (p *Pool) share() (int, bool) {
+	if len(p.o.ClassWeights) == 0 {
+		return 0, false
+	}
+	var class int
+	var lag float64
+	found := false
+	for _, w := range p.w {
+		if w.want != nil {
+			continue
+		}
+		v := math.Inf(1)
+		if wt := p.o.ClassWeights[w.class]; wt > 0 {
+			v = float64(p.served[w.class]) / wt
+		}
+		if !found || v < lag {
+			class, lag, found = w.class, v, true
+		}
+	}
+	return class, found
+}
+
+// Internal function flagging a resource held by a class lower
+// than the given one, the lowest first, so its holder can
+// release it early.
+// Must be called with the lock held.
+func (p *Pool) preempt(class int) {
+	var m *record
+	for _, v := range p.meta {
+		if v.borrowed.IsZero() || v.preempted || v.class >= class {
+			continue
+		}
+		if m == nil || v.class < m.class {
+			m = v
+		}
+	}
+	if m != nil {
+		m.preempted = true
+	}
+}
+
+// Check if the holder of a resource is asked to release it early
+// because a caller with a higher priority class is waiting.
+// Only happens with Options.AllowPreemption.
+func (p *Pool) Preempted(r Resource) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	m, ok := p.meta[identity(r)]
+	return ok && m.preempted
+}
+
+// Unblock every caller waiting for a resource.
+// They return ErrWaitersCancelled. Callers arriving afterwards
+// wait as usual. Useful in an emergency shutdown or before a
+// reconfiguration.
+func (p *Pool) CancelWaiters() {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.dismiss(ErrWaitersCancelled)
+}
+
+// Internal function unblocking all waiters with err.
+// Must be called with the lock held.
+func (p *Pool) dismiss(err error) {
+	for _, w := range p.w {
+		w.err = err
+		close(w.c)
+	}
+	p.w = nil
+}
+
+// Internal function removing a waiter from the queue.
+// Returns false if it is no longer waiting.
+// Must be called with the lock held.
+func (p *Pool) removeWaiter(w *waiter) bool {
+	for i, v := range p.w {
+		if v == w {
+			p.w = append(p.w[:i], p.w[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *waitStats) record(d time.Duration) {
+	if d > 0 {
+		// Acquires served at once say nothing of how long to wait
+		s.observe(d)
+	}
+	s.n++
+	s.sum += d
+	if d > s.max {
+		s.max = d
+	}
+}
+
+// Wait times of acquirers so far.
+// Returns the longest and the average time an Acquire had to
+// wait for a resource, including those served at once.
+func (p *Pool) WaitTime() (max, avg time.Duration) {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	if p.ws.n == 0 {
+		return 0, 0
+	}
+	return p.ws.max, p.ws.sum / time.Duration(p.ws.n)
+}
+
+// Longest current wait among blocked acquirers.
+// Returns 0 if nobody is waiting. Alert on it to detect
+// callers being starved of resources.
+func (p *Pool) Starvation() time.Duration {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	var d time.Duration
+	now := time.Now()
+	for _, w := range p.w {
+		if t := now.Sub(w.t); t > d {
+			d = t
+		}
+	}
+	return d
+}
+
+// Internal function keeping a wait among the recent ones.
+func (s *waitStats) observe(d time.Duration) {
+	if len(s.recent) < waitSamples {
+		s.recent = append(s.recent, d)
+		return
+	}
+	s.recent[s.rn] = d
+	s.rn = (s.rn + 1) % waitSamples
+}
+
+// Timeout of the next acquire.
+// Options.Timeout unless Options.AdaptiveTimeout is set, in which
+// case it is 3 times the 99th percentile of recent waits of
+// acquires that blocked, timeouts counting as Options.Timeout,
+// between Options.MinTimeout, a tenth of Options.Timeout if not
+// set, and Options.Timeout.
+func (p *Pool) EffectiveTimeout() time.Duration {
+	p.l.RLock()
+	timeout := p.o.Timeout
+	if !p.o.AdaptiveTimeout {
+		p.l.RUnlock()
+		return timeout
+	}
+	v := append([]time.Duration(nil), p.ws.recent...)
+	p.l.RUnlock()
+	if len(v) == 0 {
+		return timeout
+	}
+	sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+	d := 3 * v[(len(v)-1)*99/100]
+	floor := p.o.MinTimeout
+	if floor <= 0 {
+		floor = timeout / 10
+	}
+	if d < floor {
+		d = floor
+	}
+	if d > timeout {
+		d = timeout
+	}
+	return d
+}
+
+// Change Options.Timeout of a running pool. Acquires already
+// waiting keep the timeout they started with.
+func (p *Pool) SetTimeout(d time.Duration) {
+	p.l.Lock()
+	p.o.Timeout = d
+	p.l.Unlock()
+}
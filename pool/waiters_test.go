@@ -0,0 +1,33 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancelWaiters(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 5 * time.Second})
+	p.Acquire()
+	ch := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { _, err := p.Acquire(); ch <- err }()
+	}
+	time.Sleep(20 * time.Millisecond)
+	p.CancelWaiters()
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-ch:
+			if err != ErrWaitersCancelled {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("hang")
+		}
+	}
+	go func() { _, err := p.Acquire(); ch <- err }()
+	time.Sleep(10 * time.Millisecond)
+	p.Close()
+	if err := <-ch; err != ErrPoolClosed {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,65 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWarm(t *testing.T) {
+	r := newT()
+	p, _ := Initialize(r, Options{PoolSize: 5, Timeout: 10 * time.Millisecond, Lazy: true})
+	if p.s != 0 {
+		t.Fatal(p.s)
+	}
+	if err := p.Warm(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	if p.s != 3 || len(p.idle) != 3 {
+		t.Fatal(p.s, len(p.idle))
+	}
+	var rs []Resource
+	for i := 0; i < 5; i++ {
+		a, err := p.Acquire()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, a)
+	}
+	if _, err := p.Acquire(); err == nil {
+		t.Fatal("expected timeout")
+	}
+	for _, a := range rs {
+		p.Release(a)
+	}
+	if len(p.idle) != 5 || p.s != 5 {
+		t.Fatal(len(p.idle), p.s)
+	}
+}
+
+type slowWarm struct {
+	*tres
+	d time.Duration
+}
+
+func (s slowWarm) Add() (Resource, error) {
+	time.Sleep(s.d)
+	return s.tres.Add()
+}
+
+func TestWarmCancel(t *testing.T) {
+	p, _ := Initialize(slowWarm{newT(), 300 * time.Millisecond}, Options{PoolSize: 2, Lazy: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.Warm(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 200*time.Millisecond {
+		t.Fatal(d)
+	}
+	if s := p.Stats(); s.Size != 0 {
+		t.Fatal(s)
+	}
+}
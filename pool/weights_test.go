@@ -0,0 +1,40 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClassWeights(t *testing.T) {
+	p, _ := Initialize(newT(), Options{PoolSize: 1, Timeout: 5 * time.Second, ClassWeights: map[int]float64{1: 0.3, 2: 0.7}})
+	var n [3]int64
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	for c := 1; c <= 2; c++ {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for !stop.Load() {
+					r, err := p.AcquirePriority(c)
+					if err != nil {
+						continue
+					}
+					atomic.AddInt64(&n[c], 1)
+					time.Sleep(100 * time.Microsecond)
+					p.Release(r)
+				}
+			}(c)
+		}
+	}
+	time.Sleep(500 * time.Millisecond)
+	stop.Store(true)
+	wg.Wait()
+	f := float64(n[1]) / float64(n[1]+n[2])
+	t.Log(n, f)
+	if f < 0.25 || f > 0.35 {
+		t.Fatal(f)
+	}
+}
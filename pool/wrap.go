@@ -0,0 +1,36 @@
+package pool
+
+import "reflect"
+
+type (
+	// Resource wrapping another one, e.g. to instrument it. The
+	// pool tracks the innermost resource, so a wrapper is owned,
+	// released and checked for duplicates as the resource it wraps.
+	WrappedResource interface {
+		Resource
+		Unwrap() Resource // Wrapped resource
+	}
+)
+
+// Internal function checking that r and the resource it wraps
+// can be told apart by identity, which panics for a value holding
+// a slice, map or func.
+func trackable(r Resource) bool {
+	return reflect.ValueOf(r).Comparable() && reflect.ValueOf(identity(r)).Comparable()
+}
+
+// Internal function returning the resource the pool tracks r as,
+// r unwrapped as far as it goes.
+func identity(r Resource) Resource {
+	for {
+		w, ok := r.(WrappedResource)
+		if !ok {
+			return r
+		}
+		u := w.Unwrap()
+		if u == nil {
+			return r
+		}
+		r = u
+	}
+}
@@ -0,0 +1,59 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+type wrapRes struct {
+	*tres
+}
+
+func (s *wrapRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	return &wrapRes{r.(*tres)}, nil
+}
+func (s *wrapRes) Unwrap() Resource { return s.tres }
+
+func TestWrappedResource(t *testing.T) {
+	p, err := Initialize(&wrapRes{newT()}, Options{PoolSize: 2, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := p.Acquire()
+	w := r.(*wrapRes)
+	if !p.Owns(r) || !p.Owns(w.tres) || !p.Owns(&wrapRes{w.tres}) {
+		t.Fatal("owns")
+	}
+	if err := p.Release(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Release(&wrapRes{w.tres}); err != ErrNotAcquired {
+		t.Fatal(err)
+	}
+	if p.Stats().Idle != 2 {
+		t.Fatal(p.Stats())
+	}
+}
+
+func TestWrappedResourceVisible(t *testing.T) {
+	a, _ := Initialize(&wrapRes{newT()}, Options{PoolSize: 2, Timeout: time.Second})
+	b, _ := Initialize(&wrapRes{newT()}, Options{PoolSize: 2, Timeout: time.Second, Lazy: true})
+	v, _ := b.Acquire()
+	b.Release(v)
+	r, _ := a.Acquire()
+	if hs := a.Holders(); len(hs) != 1 || hs[0].Resource != r {
+		t.Fatal(hs)
+	}
+	if h := a.Health(); len(h) != 2 {
+		t.Fatal(h)
+	}
+	for v := range a.Health() {
+		if _, ok := v.(*wrapRes); !ok {
+			t.Fatal("unwrapped", v)
+		}
+	}
+	if err := a.ReleaseTo(b, r); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMinAgeBeforeEvict(t *testing.T) {
+	b := newT()
+	b.evict = true
+	p, _ := Initialize(b, Options{PoolSize: 2, Timeout: time.Second, MaxLifetime: time.Nanosecond, MinAgeBeforeEvict: time.Hour})
+	p.l.Lock()
+	a := p.idle[0]
+	p.meta[p.idle[1]].created = time.Now().Add(-2 * time.Hour)
+	o := p.idle[1]
+	p.l.Unlock()
+	if v := p.EvictionPreview(); len(v) != 1 || v[0] != o {
+		t.Fatal(v)
+	}
+	p.refreshPool(nil)
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.idle[0] != a || p.idle[1] == o || a.(*tres).evicted.Load() {
+		t.Fatal("young evicted")
+	}
+}
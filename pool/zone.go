@@ -0,0 +1,61 @@
+package pool
+
+type (
+	// Resource living in a zone, e.g. a connection to a replica in
+	// one availability zone. AcquireZone hands out resources of the
+	// zone asked for first.
+	ZonedResource interface {
+		Resource
+		Zone() string // Zone of the resource
+	}
+)
+
+// Acquire an idle resource in zone preferred, or any resource as
+// with Acquire if there is none. CrossZone reports which one the
+// caller got.
+func (p *Pool) AcquireZone(preferred string) (Resource, error) {
+	r, _, err := p.checkout(request{timeout: p.EffectiveTimeout(), zone: preferred})
+	if r == nil {
+		return r, err
+	}
+	p.l.Lock()
+	if m, ok := p.meta[identity(r)]; ok {
+		m.cross = zoneOf(r) != preferred
+	}
+	p.l.Unlock()
+	return r, err
+}
+
+// Report if r, acquired with AcquireZone, is outside the zone
+// asked for. False once r is released.
+func (p *Pool) CrossZone(r Resource) bool {
+	p.l.RLock()
+	defer p.l.RUnlock()
+	m, ok := p.meta[identity(r)]
+	return ok && m.cross
+}
+
+// Internal function taking an idle resource in zone.
+// Returns nil if there is none or zone is empty.
+// Must be called with the lock held.
+func (p *Pool) local(zone string) Resource {
+	if zone == "" {
+		return nil
+	}
+	for _, r := range p.idle {
+		if zoneOf(r) == zone && !p.dedicated(r) && !p.broken(r) && valid(r) {
+			p.pick(r)
+			return r
+		}
+	}
+	return nil
+}
+
+// Internal function returning the zone of r, empty if it is not
+// a ZonedResource.
+func zoneOf(r Resource) string {
+	if z, ok := r.(ZonedResource); ok {
+		return z.Zone()
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package pool
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+type zoneRes struct {
+	*tres
+	z string
+	n *int32
+}
+
+func (s *zoneRes) Add() (Resource, error) {
+	r, _ := s.tres.Add()
+	z := "a"
+	if atomic.AddInt32(s.n, 1)%2 == 0 {
+		z = "b"
+	}
+	return &zoneRes{r.(*tres), z, s.n}, nil
+}
+func (s *zoneRes) Zone() string { return s.z }
+
+func TestAcquireZone(t *testing.T) {
+	p, _ := Initialize(&zoneRes{newT(), "", new(int32)}, Options{PoolSize: 4})
+	var bs []Resource
+	for i := 0; i < 2; i++ {
+		r, err := p.AcquireZone("b")
+		if err != nil || r.(*zoneRes).z != "b" || p.CrossZone(r) {
+			t.Fatal(i, err)
+		}
+		bs = append(bs, r)
+	}
+	r, err := p.AcquireZone("b")
+	if err != nil || r.(*zoneRes).z != "a" || !p.CrossZone(r) {
+		t.Fatal(err)
+	}
+	p.Release(r)
+	if p.CrossZone(r) {
+		t.Fatal()
+	}
+	p.Release(bs[0])
+	if r, _ := p.AcquireZone("b"); r != bs[0] {
+		t.Fatal()
+	}
+}